@@ -13,6 +13,7 @@ var (
 	file         *os.File
 	logger       *log.Logger
 	currentPath  string
+	redact       func(string) string
 	userCacheDir = os.UserCacheDir
 )
 
@@ -83,6 +84,14 @@ func Initialized() bool {
 	return logger != nil
 }
 
+// SetRedactor sets a function applied to every message before it is written, e.g. to mask
+// secrets. A nil function disables redaction.
+func SetRedactor(fn func(string) string) {
+	mu.Lock()
+	defer mu.Unlock()
+	redact = fn
+}
+
 // Infof writes an informational log line. It is a safe no-op before initialization.
 func Infof(format string, args ...any) {
 	printf("INFO", format, args...)
@@ -101,11 +110,16 @@ func Errorf(format string, args ...any) {
 func printf(level, format string, args ...any) {
 	mu.Lock()
 	l := logger
+	r := redact
 	mu.Unlock()
 	if l == nil {
 		return
 	}
-	l.Printf("%s: %s", level, fmt.Sprintf(format, args...))
+	message := fmt.Sprintf(format, args...)
+	if r != nil {
+		message = r(message)
+	}
+	l.Printf("%s: %s", level, message)
 }
 
 func defaultPath() (string, error) {
@@ -103,3 +103,25 @@ func TestHelpersAreSafeNoOpsBeforeInitialization(t *testing.T) {
 		t.Fatal("Initialized() = true, want false")
 	}
 }
+
+func TestSetRedactorMasksMessages(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "localdev.log")
+	if err := InitWithPath(logPath); err != nil {
+		t.Fatalf("InitWithPath() error = %v", err)
+	}
+	SetRedactor(func(s string) string { return strings.ReplaceAll(s, "hunter2", "****") })
+	t.Cleanup(func() { SetRedactor(nil) })
+	Errorf("login failed with password %s", "hunter2")
+	if err := Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	contents, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("read log file: %v", err)
+	}
+	if logText := string(contents); strings.Contains(logText, "hunter2") ||
+		!strings.Contains(logText, "ERROR: login failed with password ****") {
+		t.Fatalf("log contents = %q, want the password masked", logText)
+	}
+}
@@ -10,6 +10,14 @@ import (
 )
 
 func main() {
+	if handled, err := app.RunHelper(os.Args[1:]); handled {
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "Local Dev helper failed: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	if err := logger.Init(); err != nil {
 		_, _ = fmt.Fprintf(os.Stderr, "Local Dev failed to initialize logging: %v\n", err)
 		os.Exit(1)
@@ -39,10 +47,10 @@ func main() {
 		fmt.Println("🛑 Stopping all panes...")
 		stopErrorCount := a.StopPanes()
 		if stopErrorCount > 0 {
-			logger.Errorf("shutdown completed with %d stop command error(s)", stopErrorCount)
+			logger.Errorf("shutdown completed with %d error(s)", stopErrorCount)
 			_, _ = fmt.Fprintf(
 				os.Stderr,
-				"Some stop commands failed (%d); see log file: %s\n",
+				"Some panes failed to stop (%d error(s)); see log file: %s\n",
 				stopErrorCount,
 				logger.Path(),
 			)
@@ -50,6 +58,9 @@ func main() {
 		} else {
 			fmt.Println("✅ All panes stopped.")
 		}
+		if err := a.Close(); err != nil {
+			logger.Warnf("error releasing the instance lock: %v", err)
+		}
 		_ = logger.Close()
 	}()
 }
@@ -2,18 +2,31 @@ package app
 
 import (
 	"bufio"
+	"cmp"
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
+	"os/signal"
 	"path/filepath"
 	"sync"
+	"sync/atomic"
+	"text/tabwriter"
+	"time"
 
 	"github.com/jiyeol-lee/localdev/internal/logger"
+	"github.com/jiyeol-lee/localdev/pkg/command"
 	"github.com/jiyeol-lee/localdev/pkg/config"
+	"github.com/jiyeol-lee/localdev/pkg/constant"
+	"github.com/jiyeol-lee/localdev/pkg/internal/env_vars"
+	"github.com/jiyeol-lee/localdev/pkg/internal/history"
+	"github.com/jiyeol-lee/localdev/pkg/internal/session"
 	"github.com/jiyeol-lee/localdev/pkg/internal/shell"
 	"github.com/jiyeol-lee/localdev/pkg/view"
 	"github.com/rivo/tview"
+	"golang.org/x/sys/unix"
 )
 
 type AppView struct {
@@ -22,8 +35,25 @@ type AppView struct {
 
 // App represents the main application controller.
 type App struct {
-	view   *view.View
-	config *config.Config
+	view    *view.View
+	config  *config.Config
+	session *session.Recorder
+	// lock keeps a second instance from starting with the same config.
+	lock *session.Lock
+	// shuttingDown is set once the shutdown sequence has begun; another signal then forces an exit.
+	shuttingDown atomic.Bool
+	signals      chan os.Signal
+	// stopCommands maps the process groups of the running stop commands to their pane names;
+	// guarded by stopCommandsMu.
+	stopCommands   map[int]string
+	stopCommandsMu sync.Mutex
+}
+
+// RunHelper runs the internal helper requested by args, the program arguments without the
+// program name, and reports whether one was requested. Local Dev re-executes itself with such
+// arguments, e.g. to capture the environment left by the project command.
+func RunHelper(args []string) (bool, error) {
+	return env_vars.RunDumpEnvHelper(args)
 }
 
 // Run initializes and runs the application with the given configuration file name.
@@ -37,6 +67,40 @@ func Run(configFileName string) (*App, error) {
 		return nil, fmt.Errorf("error loading config: %w", err)
 	}
 
+	a.handleSignals()
+
+	configPath := resolveConfigPath(a.config.GetPath())
+	lock, err := session.AcquireLock(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("error locking config: %w", err)
+	}
+	a.lock = lock
+
+	if statePath, err := session.Path(configPath); err != nil {
+		logger.Warnf("error resolving session state path: %v", err)
+	} else {
+		state, err := session.Load(statePath)
+		if err != nil {
+			logger.Warnf("error reading previous session state: %v", err)
+		}
+		orphans := a.cleanUpOrphans(state, os.Stdin, os.Stdout)
+		a.session = session.NewRecorder(statePath, configPath)
+		if err := a.session.KeepOrphans(orphans); err != nil {
+			logger.Warnf("error recording orphaned process groups: %v", err)
+		}
+		a.view.SetSession(a.session)
+	}
+
+	if historyPath, err := history.Path(); err != nil {
+		logger.Warnf("error resolving command history path: %v", err)
+	} else {
+		store, err := history.Open(historyPath, configPath)
+		if err != nil {
+			logger.Warnf("error reading command history: %v", err)
+		}
+		a.view.SetCommandHistory(store)
+	}
+
 	if err := a.view.Run(*a.config); err != nil {
 		return nil, fmt.Errorf("error running view: %w", err)
 	}
@@ -44,16 +108,84 @@ func Run(configFileName string) (*App, error) {
 	return a, nil
 }
 
-// StopPanes stops all panes defined in the configuration and returns an error count.
-func (a *App) StopPanes() int {
-	var wg sync.WaitGroup
-	var errorMu sync.Mutex
-	errorCount := 0
-	recordError := func() {
-		errorMu.Lock()
-		defer errorMu.Unlock()
-		errorCount++
+// handleSignals shuts down like a quit from the UI when the process receives SIGTERM or SIGHUP,
+// e.g. because a supervisor stops it or its terminal is closed. A signal received once the shutdown
+// sequence has begun, including Ctrl+C, kills every pane and stop command process group and exits
+// immediately.
+func (a *App) handleSignals() {
+	a.signals = make(chan os.Signal, 1)
+	signal.Notify(a.signals, unix.SIGTERM, unix.SIGHUP)
+	go func() {
+		for sig := range a.signals {
+			if a.shuttingDown.CompareAndSwap(false, true) {
+				logger.Infof("received %s; shutting down", sig)
+				a.view.Stop()
+				continue
+			}
+			a.forceExit(sig)
+		}
+	}()
+}
+
+// beginShutdown marks the start of the shutdown sequence, from which Ctrl+C forces an exit too.
+func (a *App) beginShutdown() {
+	a.shuttingDown.Store(true)
+	signal.Notify(a.signals, unix.SIGINT)
+}
+
+// forceExit kills every pane and stop command process group and exits without waiting for the
+// shutdown sequence.
+func (a *App) forceExit(sig os.Signal) {
+	killed := a.view.KillPanes()
+	killedStopCommands := a.killStopCommands()
+	logger.Errorf(
+		"received %s during shutdown; killed %d process group(s) and exiting",
+		sig,
+		len(killed)+len(killedStopCommands),
+	)
+	for _, group := range killed {
+		_, _ = fmt.Fprintf(os.Stderr, "[%s] Killed process group %d\n", group.Name, group.Pgid)
 	}
+	for _, group := range killedStopCommands {
+		_, _ = fmt.Fprintf(os.Stderr, "[%s] Killed stop command process group %d\n", group.Name, group.Pgid)
+	}
+	_, _ = fmt.Fprintf(os.Stderr, "Received %s during shutdown; exiting without running stop commands.\n", sig)
+	_ = logger.Close()
+	os.Exit(1)
+}
+
+// Close releases the resources held by the application, such as the single-instance lock.
+func (a *App) Close() error {
+	return a.lock.Release()
+}
+
+// resolveConfigPath returns the absolute config path with symlinks resolved, so every way of
+// naming the same file shares one lock.
+func resolveConfigPath(path string) string {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	return path
+}
+
+// paneStopResult is the outcome of stopping a pane on exit.
+type paneStopResult struct {
+	name   string
+	result string
+	errors int
+	// survived reports whether the pane's start process group is still running.
+	survived bool
+}
+
+// StopPanes stops every pane defined in the configuration and returns an error count. Each pane's
+// start process group is signaled and awaited before its stop command runs. Panes are stopped
+// concurrently, except that a pane waits for the panes that depend on it and, with the reverse
+// shutdown order, for the pane declared after it. A table of the results is printed at the end.
+func (a *App) StopPanes() int {
+	a.beginShutdown()
 	colors := []string{
 		"\033[38;2;255;165;0m",   // Orange
 		"\033[38;2;255;255;0m",   // Yellow
@@ -66,88 +198,197 @@ func (a *App) StopPanes() int {
 		"\033[38;2;75;0;130m",    // Indigo
 		"\033[38;2;255;105;180m", // Pink
 	}
-	reset := "\033[0m"
 
 	skipped := a.view.GetManuallyStoppedPaneNames()
+	waits := a.config.GetShutdownWaits()
+	results := make([]paneStopResult, len(a.config.Panes))
+	done := make([]chan struct{}, len(a.config.Panes))
+	for i := range done {
+		done[i] = make(chan struct{})
+	}
 
+	var wg sync.WaitGroup
 	for i, pane := range a.config.Panes {
-		if skipped[pane.Name] {
-			continue
-		}
-		pane := pane // capture
-		color := colors[i%len(colors)]
-
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-
-			sh := shell.Current()
-			dir := pane.Dir
-			projectDir := a.config.GetProjectDir()
-			if projectDir != "" {
-				dir = filepath.Join(projectDir, pane.Dir)
-			}
-			cmd := exec.Command(sh, "-c", pane.Stop)
-			cmd.Dir = dir
-			cmd.Env = append(os.Environ(), a.view.GetEnvVars()...)
-			stdout, err := cmd.StdoutPipe()
-			stderr, err2 := cmd.StderrPipe()
-			if err != nil {
-				logger.Errorf(
-					"error creating stdout pipe for stop command for pane %s: %v",
-					pane.Name,
-					err,
-				)
-				recordError()
-			}
-			if err2 != nil {
-				logger.Errorf(
-					"error creating stderr pipe for stop command for pane %s: %v",
-					pane.Name,
-					err2,
-				)
-				recordError()
-			}
-			if err != nil || err2 != nil {
-				return
+			defer close(done[i])
+			for _, j := range waits[i] {
+				<-done[j]
 			}
+			results[i] = a.stopPane(i, pane, colors[i%len(colors)], skipped[pane.Name])
+		}()
+	}
+	wg.Wait()
 
-			if err := cmd.Start(); err != nil {
-				logger.Errorf("failed to start stop command for pane %s: %v", pane.Name, err)
-				recordError()
-				return
-			}
+	errorCount := 0
+	survived := false
+	for _, r := range results {
+		errorCount += r.errors
+		survived = survived || r.survived
+	}
+	// Keep the session state of surviving process groups for the next launch to clean up.
+	if !survived {
+		if err := a.session.Remove(); err != nil {
+			logger.Warnf("error removing session state: %v", err)
+		}
+	}
+	writeStopResults(os.Stdout, results)
+	return errorCount
+}
 
-			var scanWg sync.WaitGroup
-			scanAndPrint := func(stream string, r io.ReadCloser) {
-				defer scanWg.Done()
-				scanner := bufio.NewScanner(r)
-				for scanner.Scan() {
-					fmt.Printf("%s[%s] %s%s\n", color, pane.Name, scanner.Text(), reset)
-				}
-				if err := scanner.Err(); err != nil {
-					logger.Errorf(
-						"error reading %s for stop command for pane %s: %v",
-						stream,
-						pane.Name,
-						err,
-					)
-					recordError()
-				}
-			}
+// stopPane stops the start process group of the pane at index and then, unless it was stopped
+// manually, runs its stop command, printing the output prefixed with the pane name in color.
+func (a *App) stopPane(index int, pane config.ConfigPane, color string, manuallyStopped bool) paneStopResult {
+	reset := constant.AnsiColor.Reset
+	r := paneStopResult{name: pane.Name}
 
-			scanWg.Add(2)
-			go scanAndPrint("stdout", stdout)
-			go scanAndPrint("stderr", stderr)
+	group, survived := a.view.TerminatePane(index, func(group view.PaneProcessGroup, step command.StopStep) {
+		fmt.Printf(
+			"%s[%s] Sending %s to process group %d (waiting up to %s)%s\n",
+			color,
+			group.Name,
+			unix.SignalName(step.Signal),
+			group.Pgid,
+			step.Timeout,
+			reset,
+		)
+	})
+	if survived {
+		logger.Errorf("process group %d of pane %s is still running", group.Pgid, group.Name)
+		fmt.Printf(
+			"%s[%s] Process group %d is still running; kill it with: kill -KILL -%d%s\n",
+			constant.AnsiColor.Red,
+			group.Name,
+			group.Pgid,
+			group.Pgid,
+			reset,
+		)
+		r.survived = true
+		r.errors++
+	}
 
-			if err := cmd.Wait(); err != nil {
-				logger.Errorf("stop command for pane %s exited with error: %v", pane.Name, err)
-				recordError()
-			}
-			scanWg.Wait()
-		}()
+	switch {
+	case manuallyStopped:
+		r.result = "skipped (stopped manually)"
+	case pane.Stop == "":
+		r.result = "skipped (no stop command)"
+	default:
+		dir := pane.Dir
+		if projectDir := a.config.GetProjectDir(); projectDir != "" {
+			dir = filepath.Join(projectDir, pane.Dir)
+		}
+		r.result = a.runStopCommand(pane, dir, a.view.GetPaneCommandEnv(index), func(line string) {
+			fmt.Printf("%s[%s] %s%s\n", color, pane.Name, a.view.MaskSecrets(line), reset)
+		})
+		if r.result != "ok" {
+			r.errors++
+		}
 	}
+	if survived {
+		r.result += fmt.Sprintf("; process group %d still running", group.Pgid)
+	}
+	return r
+}
 
-	wg.Wait()
-	return errorCount
+// runStopCommand runs the stop command of pane in dir with env, passing each output line to onLine,
+// and kills its process group once the pane's stop command timeout expires. It returns "ok" or a
+// description of the failure.
+func (a *App) runStopCommand(pane config.ConfigPane, dir string, env []string, onLine func(line string)) string {
+	timeout := cmp.Or(pane.StopCommandTimeout, constant.DefaultStopCommandTimeout)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, shell.Current(), "-c", pane.Stop)
+	cmd.Dir = dir
+	cmd.Env = env
+	cmd.SysProcAttr = &unix.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return unix.Kill(-cmd.Process.Pid, unix.SIGKILL)
+	}
+	// Stop waiting for the output of background children that outlive the killed process group.
+	cmd.WaitDelay = time.Second
+	// A pipe that is not an *os.File makes Wait copy the output itself, so WaitDelay also
+	// applies to it.
+	output, outputWriter := io.Pipe()
+	cmd.Stdout = outputWriter
+	cmd.Stderr = outputWriter
+	scanDone := make(chan struct{})
+	go func() {
+		defer close(scanDone)
+		scanner := bufio.NewScanner(output)
+		for scanner.Scan() {
+			onLine(scanner.Text())
+		}
+		if err := scanner.Err(); err != nil {
+			logger.Errorf("error reading output of stop command for pane %s: %v", pane.Name, err)
+		}
+		// Keep draining so the command never blocks on a line too long to scan.
+		_, _ = io.Copy(io.Discard, output)
+	}()
+
+	err := cmd.Start()
+	if err == nil {
+		a.trackStopCommand(cmd.Process.Pid, pane.Name)
+		err = cmd.Wait()
+		a.untrackStopCommand(cmd.Process.Pid)
+	}
+	_ = outputWriter.Close()
+	<-scanDone
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		logger.Errorf("stop command for pane %s timed out after %s", pane.Name, timeout)
+		return fmt.Sprintf("timed out after %s", timeout)
+	}
+	if err != nil {
+		logger.Errorf("stop command for pane %s exited with error: %v", pane.Name, err)
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() >= 0 {
+			return fmt.Sprintf("failed (exit code %d)", exitErr.ExitCode())
+		}
+		return fmt.Sprintf("failed: %v", err)
+	}
+	return "ok"
+}
+
+// trackStopCommand records the process group of a running stop command of the named pane.
+func (a *App) trackStopCommand(pgid int, name string) {
+	a.stopCommandsMu.Lock()
+	defer a.stopCommandsMu.Unlock()
+	if a.stopCommands == nil {
+		a.stopCommands = make(map[int]string)
+	}
+	a.stopCommands[pgid] = name
+}
+
+// untrackStopCommand forgets the process group of a stop command that exited.
+func (a *App) untrackStopCommand(pgid int) {
+	a.stopCommandsMu.Lock()
+	defer a.stopCommandsMu.Unlock()
+	delete(a.stopCommands, pgid)
+}
+
+// killStopCommands sends SIGKILL to the process group of every running stop command and returns
+// the process groups it signaled.
+func (a *App) killStopCommands() []view.PaneProcessGroup {
+	a.stopCommandsMu.Lock()
+	defer a.stopCommandsMu.Unlock()
+	var killed []view.PaneProcessGroup
+	for pgid, name := range a.stopCommands {
+		if err := unix.Kill(-pgid, unix.SIGKILL); err != nil {
+			logger.Errorf("error killing stop command process group %d of pane %s: %v", pgid, name, err)
+			continue
+		}
+		killed = append(killed, view.PaneProcessGroup{Name: name, Pgid: pgid})
+	}
+	return killed
+}
+
+// writeStopResults prints a table of the stop results, one row per pane.
+func writeStopResults(w io.Writer, results []paneStopResult) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "PANE\tRESULT")
+	for _, r := range results {
+		_, _ = fmt.Fprintf(tw, "%s\t%s\n", r.name, r.result)
+	}
+	_ = tw.Flush()
 }
@@ -0,0 +1,143 @@
+package app
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jiyeol-lee/localdev/pkg/config"
+	"github.com/jiyeol-lee/localdev/pkg/internal/session"
+	"github.com/jiyeol-lee/localdev/pkg/view"
+	"golang.org/x/sys/unix"
+)
+
+func Test_runStopCommand(t *testing.T) {
+	tests := []struct {
+		name      string
+		pane      config.ConfigPane
+		want      string
+		wantLines []string
+	}{
+		{
+			name:      "success",
+			pane:      config.ConfigPane{Name: "api", Stop: "echo stopping; echo done >&2"},
+			want:      "ok",
+			wantLines: []string{"stopping", "done"},
+		},
+		{
+			name: "non-zero exit",
+			pane: config.ConfigPane{Name: "api", Stop: "exit 3"},
+			want: "failed (exit code 3)",
+		},
+		{
+			name: "timeout kills background children",
+			pane: config.ConfigPane{
+				Name:               "api",
+				Stop:               "sleep 30 & echo waiting; wait",
+				StopCommandTimeout: 200 * time.Millisecond,
+			},
+			want:      "timed out after 200ms",
+			wantLines: []string{"waiting"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var lines []string
+			start := time.Now()
+			got := (&App{}).runStopCommand(tt.pane, t.TempDir(), nil, func(line string) {
+				lines = append(lines, line)
+			})
+			if got != tt.want {
+				t.Errorf("runStopCommand() = %q, want %q", got, tt.want)
+			}
+			slices.Sort(lines)
+			slices.Sort(tt.wantLines)
+			if !slices.Equal(lines, tt.wantLines) {
+				t.Errorf("output lines = %q, want %q", lines, tt.wantLines)
+			}
+			if elapsed := time.Since(start); elapsed > 5*time.Second {
+				t.Errorf("runStopCommand() took %s", elapsed)
+			}
+		})
+	}
+}
+
+func Test_writeStopResults(t *testing.T) {
+	var b bytes.Buffer
+	writeStopResults(&b, []paneStopResult{
+		{name: "api", result: "ok"},
+		{name: "database", result: "skipped (stopped manually)"},
+	})
+	want := strings.Join([]string{
+		"PANE      RESULT",
+		"api       ok",
+		"database  skipped (stopped manually)",
+		"",
+	}, "\n")
+	if got := b.String(); got != want {
+		t.Errorf("writeStopResults() = %q, want %q", got, want)
+	}
+}
+
+func TestApp_killStopCommands(t *testing.T) {
+	a := &App{}
+	done := make(chan string, 1)
+	go func() {
+		pane := config.ConfigPane{Name: "api", Stop: "sleep 30 & wait"}
+		done <- a.runStopCommand(pane, t.TempDir(), nil, func(string) {})
+	}()
+
+	var killed []view.PaneProcessGroup
+	deadline := time.Now().Add(5 * time.Second)
+	for len(killed) == 0 && time.Now().Before(deadline) {
+		time.Sleep(20 * time.Millisecond)
+		killed = a.killStopCommands()
+	}
+	if len(killed) != 1 || killed[0].Name != "api" {
+		t.Fatalf("killStopCommands() = %+v, want the api stop command", killed)
+	}
+
+	select {
+	case got := <-done:
+		if !strings.HasPrefix(got, "failed") {
+			t.Errorf("runStopCommand() = %q, want a failure", got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("stop command was not killed")
+	}
+	if got := a.killStopCommands(); len(got) != 0 {
+		t.Errorf("killStopCommands() after exit = %+v, want none", got)
+	}
+}
+
+func TestApp_cleanUpOrphans_ReturnsOrphansLeftRunning(t *testing.T) {
+	cmd := exec.Command("sleep", "30")
+	cmd.SysProcAttr = &unix.SysProcAttr{Setpgid: true}
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = unix.Kill(-cmd.Process.Pid, unix.SIGKILL); _ = cmd.Wait() })
+	state := &session.State{
+		PID:   os.Getpid(),
+		Panes: []session.Pane{{Name: "api", Pgid: cmd.Process.Pid, StartedAt: time.Now()}},
+	}
+	// Without a terminal the orphans are only reported.
+	in, err := os.Open(os.DevNull)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer in.Close()
+
+	var out bytes.Buffer
+	got := (&App{config: &config.Config{}}).cleanUpOrphans(state, in, &out)
+	if len(got) != 1 || got[0].Pgid != cmd.Process.Pid {
+		t.Errorf("cleanUpOrphans() = %+v, want the running api process group", got)
+	}
+	if !strings.Contains(out.String(), "[api] process group") {
+		t.Errorf("output = %q, want the orphan listed", out.String())
+	}
+}
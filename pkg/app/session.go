@@ -0,0 +1,180 @@
+package app
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/jiyeol-lee/localdev/internal/logger"
+	"github.com/jiyeol-lee/localdev/pkg/command"
+	"github.com/jiyeol-lee/localdev/pkg/config"
+	"github.com/jiyeol-lee/localdev/pkg/internal/env_vars"
+	"github.com/jiyeol-lee/localdev/pkg/internal/mask"
+	"github.com/jiyeol-lee/localdev/pkg/internal/session"
+	"github.com/jiyeol-lee/localdev/pkg/view"
+	"golang.org/x/sys/unix"
+	"golang.org/x/term"
+)
+
+// cleanUpOrphans looks for pane process groups that a previous session with the same config left
+// running, e.g. because the terminal was closed, and asks whether to kill them or run their stop
+// commands. Without a terminal to ask on, the orphans are only reported. It returns the orphans
+// that are still running afterwards.
+func (a *App) cleanUpOrphans(state *session.State, in *os.File, out io.Writer) []session.Pane {
+	orphans := state.Orphans(command.ProcessGroupAlive, command.ProcessStartTime)
+	if len(orphans) == 0 {
+		return nil
+	}
+
+	_, _ = fmt.Fprintf(
+		out,
+		"⚠️ A previous Local Dev session started at %s left %d process group(s) running:\n",
+		state.StartedAt.Format("2006-01-02 15:04:05"),
+		len(orphans),
+	)
+	for _, orphan := range orphans {
+		_, _ = fmt.Fprintf(
+			out,
+			"  [%s] process group %d, started at %s\n",
+			orphan.Name,
+			orphan.Pgid,
+			orphan.StartedAt.Format("15:04:05"),
+		)
+	}
+	if !term.IsTerminal(int(in.Fd())) {
+		logger.Warnf("left %d orphaned process group(s) from a previous session running", len(orphans))
+		return orphans
+	}
+
+	_, _ = fmt.Fprint(out, "[k] kill them, [s] run their stop commands, [i] leave them running: ")
+	answer, _ := bufio.NewReader(in).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "k":
+		for _, orphan := range orphans {
+			killOrphan(a.config, orphan, out)
+		}
+	case "s":
+		for _, orphan := range orphans {
+			a.runOrphanStopCommand(orphan, out)
+		}
+		for _, orphan := range orphans {
+			if command.ProcessGroupAlive(orphan.Pgid) {
+				_, _ = fmt.Fprintf(
+					out,
+					"[%s] Process group %d is still running; kill it with: kill -KILL -%d\n",
+					orphan.Name,
+					orphan.Pgid,
+					orphan.Pgid,
+				)
+			}
+		}
+	default:
+		_, _ = fmt.Fprintln(out, "Leaving them running.")
+	}
+	return slices.DeleteFunc(orphans, func(orphan session.Pane) bool {
+		return !command.ProcessGroupAlive(orphan.Pgid)
+	})
+}
+
+// findPane returns the configured pane with the given name.
+func findPane(cfg *config.Config, name string) (config.ConfigPane, bool) {
+	for _, pane := range cfg.Panes {
+		if pane.Name == name {
+			return pane, true
+		}
+	}
+	return config.ConfigPane{}, false
+}
+
+// killOrphan stops an orphaned process group with the stop sequence of its pane.
+func killOrphan(cfg *config.Config, orphan session.Pane, out io.Writer) {
+	pane, _ := findPane(cfg, orphan.Name)
+	exited, err := command.TerminateProcessGroup(
+		orphan.Pgid,
+		view.PaneStopSteps(pane),
+		func(step command.StopStep) {
+			_, _ = fmt.Fprintf(
+				out,
+				"[%s] Sending %s to process group %d (waiting up to %s)\n",
+				orphan.Name,
+				unix.SignalName(step.Signal),
+				orphan.Pgid,
+				step.Timeout,
+			)
+		},
+	)
+	if err != nil {
+		logger.Errorf("error stopping orphaned process group %d of pane %s: %v", orphan.Pgid, orphan.Name, err)
+	}
+	if !exited {
+		_, _ = fmt.Fprintf(out, "[%s] Process group %d is still running\n", orphan.Name, orphan.Pgid)
+	}
+}
+
+// runOrphanStopCommand runs the stop command of the pane of an orphaned process group. The command
+// gets the env and env_file settings of the project and the pane; the changes made by the project
+// command and the pane setup command are not known before the new session starts.
+func (a *App) runOrphanStopCommand(orphan session.Pane, out io.Writer) {
+	cfg := a.config
+	pane, ok := findPane(cfg, orphan.Name)
+	if !ok {
+		_, _ = fmt.Fprintf(out, "[%s] Pane is no longer configured; skipping its stop command\n", orphan.Name)
+		return
+	}
+	if pane.Stop == "" {
+		_, _ = fmt.Fprintf(out, "[%s] Pane has no stop command\n", orphan.Name)
+		return
+	}
+	dir := pane.Dir
+	if projectDir := cfg.GetProjectDir(); projectDir != "" {
+		dir = filepath.Join(projectDir, pane.Dir)
+	}
+	env, err := orphanStopCommandEnv(cfg, pane, dir)
+	if err != nil {
+		logger.Errorf("error loading env for stop command of pane %s: %v", pane.Name, err)
+		_, _ = fmt.Fprintf(out, "[%s] Failed to load env: %v\n", pane.Name, err)
+		return
+	}
+	masker := newStopOutputMasker(cfg, env)
+
+	result := a.runStopCommand(pane, dir, env, func(line string) {
+		_, _ = fmt.Fprintf(out, "[%s] %s\n", pane.Name, masker.Mask(line))
+	})
+	if result != "ok" {
+		_, _ = fmt.Fprintf(out, "[%s] Stop command %s\n", pane.Name, result)
+	}
+}
+
+// orphanStopCommandEnv returns the current environment overridden by the project and pane env settings.
+func orphanStopCommandEnv(cfg *config.Config, pane config.ConfigPane, dir string) ([]string, error) {
+	projectVars, projectFiles := cfg.GetProjectEnv()
+	projectEnv, err := env_vars.Load(projectVars, projectFiles, cfg.GetProjectDir())
+	if err != nil {
+		return nil, err
+	}
+	paneEnv, err := env_vars.Load(pane.Env, pane.EnvFile, dir)
+	if err != nil {
+		return nil, err
+	}
+	return env_vars.Set(env_vars.Set(os.Environ(), projectEnv), paneEnv), nil
+}
+
+// newStopOutputMasker returns a Masker for the configured patterns and the values of the masked
+// env vars in env.
+func newStopOutputMasker(cfg *config.Config, env []string) *mask.Masker {
+	envNames, patterns := cfg.GetMaskSettings()
+	masker, err := mask.New(patterns)
+	if err != nil {
+		return nil
+	}
+	for _, kv := range env {
+		if name, value, ok := strings.Cut(kv, "="); ok && mask.MatchName(envNames, name) {
+			masker.AddValues(value)
+		}
+	}
+	return masker
+}
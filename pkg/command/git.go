@@ -1,48 +1,335 @@
 package command
 
 import (
-	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"strconv"
 	"strings"
-
-	"github.com/jiyeol-lee/localdev/pkg/internal/shell"
 )
 
+var errNotGitRepository = errors.New("not a git repository")
+
 // BranchSyncStatus represents the ahead/behind status of a git branch relative to its remote.
 type BranchSyncStatus struct {
 	Behind int `json:"behind"`
 	Ahead  int `json:"ahead"`
 }
 
-// GetCurrentBranch returns the current branch name of the git repository in the specified directory.
-func GetCurrentBranch(dir string) (string, error) {
-	cmd := exec.Command("git", "branch", "--show-current")
+// GitStatus is a snapshot of the git information shown in a pane title.
+type GitStatus struct {
+	IsRepo bool
+	// Branch is empty when HEAD is detached.
+	Branch string
+	// HeadSHA is the abbreviated commit HEAD points to; empty before the first commit.
+	HeadSHA string
+	// Upstream is the branch's configured upstream, e.g. "origin/main"; empty when none is set.
+	Upstream string
+	// Sync is nil when the branch has no upstream.
+	Sync       *BranchSyncStatus
+	Staged     int
+	Unstaged   int
+	Untracked  int
+	Conflicted int
+	Stashes    int
+	// Operation names an in-progress operation such as "rebase" or "merge"; empty when none.
+	Operation string
+}
+
+// ReadGitStatus collects the git status of the repository containing dir.
+func ReadGitStatus(dir string) GitStatus {
+	gitDir, commonDir, err := findGitDir(dir)
+	// if git is not initialized, it will return an error
+	if err != nil {
+		return GitStatus{}
+	}
+	status := GitStatus{
+		IsRepo:    true,
+		Stashes:   countStashes(commonDir),
+		Operation: readGitOperation(gitDir),
+	}
+
+	// Avoid taking index.lock so background polling never races with the user's git commands.
+	cmd := exec.Command("git", "--no-optional-locks", "status", "--porcelain=v2", "--branch")
 	cmd.Dir = dir
 	output, err := cmd.Output()
+	if err != nil {
+		status.Branch, _ = GetCurrentBranch(dir)
+		return status
+	}
+	parseStatusPorcelainV2(string(output), &status)
+	return status
+}
+
+// parseStatusPorcelainV2 fills status from the output of git status --porcelain=v2 --branch.
+func parseStatusPorcelainV2(output string, status *GitStatus) {
+	for line := range strings.SplitSeq(output, "\n") {
+		switch {
+		case strings.HasPrefix(line, "# branch.oid "):
+			oid := strings.TrimPrefix(line, "# branch.oid ")
+			if oid != "(initial)" {
+				status.HeadSHA = oid[:min(len(oid), 7)]
+			}
+		case strings.HasPrefix(line, "# branch.head "):
+			if head := strings.TrimPrefix(line, "# branch.head "); head != "(detached)" {
+				status.Branch = head
+			}
+		case strings.HasPrefix(line, "# branch.upstream "):
+			status.Upstream = strings.TrimPrefix(line, "# branch.upstream ")
+		case strings.HasPrefix(line, "# branch.ab "):
+			fields := strings.Fields(strings.TrimPrefix(line, "# branch.ab "))
+			if len(fields) == 2 {
+				ahead, aheadErr := strconv.Atoi(strings.TrimPrefix(fields[0], "+"))
+				behind, behindErr := strconv.Atoi(strings.TrimPrefix(fields[1], "-"))
+				if aheadErr == nil && behindErr == nil {
+					status.Sync = &BranchSyncStatus{Ahead: ahead, Behind: behind}
+				}
+			}
+		case strings.HasPrefix(line, "1 "), strings.HasPrefix(line, "2 "):
+			if len(line) < 4 {
+				continue
+			}
+			if line[2] != '.' {
+				status.Staged++
+			}
+			if line[3] != '.' {
+				status.Unstaged++
+			}
+		case strings.HasPrefix(line, "u "):
+			status.Conflicted++
+		case strings.HasPrefix(line, "? "):
+			status.Untracked++
+		}
+	}
+}
+
+// countStashes returns the number of stash entries by counting the stash reflog.
+func countStashes(commonDir string) int {
+	content, err := os.ReadFile(filepath.Join(commonDir, "logs", "refs", "stash"))
+	if err != nil {
+		return 0
+	}
+	return strings.Count(string(content), "\n")
+}
+
+// readGitOperation returns the name of the operation in progress in gitDir, if any.
+func readGitOperation(gitDir string) string {
+	markers := []struct {
+		path      string
+		operation string
+	}{
+		{path: "rebase-merge", operation: "rebase"},
+		{path: "rebase-apply", operation: "rebase"},
+		{path: "MERGE_HEAD", operation: "merge"},
+		{path: "CHERRY_PICK_HEAD", operation: "cherry-pick"},
+		{path: "REVERT_HEAD", operation: "revert"},
+		{path: "BISECT_LOG", operation: "bisect"},
+	}
+	for _, marker := range markers {
+		if _, err := os.Stat(filepath.Join(gitDir, marker.path)); err == nil {
+			return marker.operation
+		}
+	}
+	return ""
+}
+
+// findGitDir returns the git directory of the repository containing dir and the common
+// directory holding its refs. The two differ only for linked worktrees.
+func findGitDir(dir string) (gitDir, commonDir string, err error) {
+	_, gitDir, commonDir, err = findRepository(dir)
+	return gitDir, commonDir, err
+}
+
+// GetRepositoryRoot returns the top-level directory of the working tree containing dir.
+func GetRepositoryRoot(dir string) (string, error) {
+	root, _, _, err := findRepository(dir)
+	return root, err
+}
+
+// findRepository walks up from dir to the first directory containing ".git" and returns that
+// working tree root together with its git and common directories.
+func findRepository(dir string) (root, gitDir, commonDir string, err error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", "", "", err
+	}
+	for d := absDir; ; d = filepath.Dir(d) {
+		dotGit := filepath.Join(d, ".git")
+		info, err := os.Stat(dotGit)
+		if err == nil {
+			gitDir = dotGit
+			if !info.IsDir() {
+				// Worktrees and submodules use a ".git" file pointing at the real git directory.
+				content, err := os.ReadFile(dotGit)
+				if err != nil {
+					return "", "", "", err
+				}
+				path, ok := strings.CutPrefix(strings.TrimSpace(string(content)), "gitdir:")
+				if !ok {
+					return "", "", "", fmt.Errorf("invalid .git file: %s", dotGit)
+				}
+				gitDir = strings.TrimSpace(path)
+				if !filepath.IsAbs(gitDir) {
+					gitDir = filepath.Join(d, gitDir)
+				}
+			}
+			commonDir = gitDir
+			if content, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+				commonDir = strings.TrimSpace(string(content))
+				if !filepath.IsAbs(commonDir) {
+					commonDir = filepath.Join(gitDir, commonDir)
+				}
+			}
+			return d, gitDir, commonDir, nil
+		}
+		if parent := filepath.Dir(d); parent == d {
+			return "", "", "", errNotGitRepository
+		}
+	}
+}
+
+// GetCurrentBranch returns the current branch name of the git repository in the specified directory.
+// It reads HEAD directly instead of running git and returns an empty name for a detached HEAD.
+func GetCurrentBranch(dir string) (string, error) {
+	gitDir, _, err := findGitDir(dir)
 	if err != nil {
 		return "", err
 	}
-	branch := strings.TrimSpace(string(output))
-	return branch, nil
+	head, err := os.ReadFile(filepath.Join(gitDir, "HEAD"))
+	if err != nil {
+		return "", err
+	}
+	ref, ok := strings.CutPrefix(strings.TrimSpace(string(head)), "ref:")
+	if !ok {
+		return "", nil
+	}
+	return strings.TrimPrefix(strings.TrimSpace(ref), "refs/heads/"), nil
 }
 
-// GetBranchSyncStatus returns the sync status of the current branch with its remote counterpart.
+// GetBranchSyncStatus returns the sync status of the current branch with its configured upstream.
 func GetBranchSyncStatus(dir string) (*BranchSyncStatus, error) {
-	sh := shell.Current()
+	cmd := exec.Command("git", "rev-list", "--left-right", "--count", "@{upstream}...HEAD")
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+	return parseLeftRightCount(string(output))
+}
+
+// parseLeftRightCount parses the "<behind>\t<ahead>" output of git rev-list --left-right --count.
+func parseLeftRightCount(output string) (*BranchSyncStatus, error) {
+	fields := strings.Fields(output)
+	if len(fields) != 2 {
+		return nil, fmt.Errorf("unexpected rev-list output: %q", output)
+	}
+	behind, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return nil, err
+	}
+	ahead, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return nil, err
+	}
+	return &BranchSyncStatus{Behind: behind, Ahead: ahead}, nil
+}
+
+// ChangedFile is a file with uncommitted changes, as reported by git status.
+type ChangedFile struct {
+	// Status is the two-letter XY status code, e.g. "M " for a staged modification or "??" for an untracked file.
+	Status string
+	Path   string
+}
+
+// Commit is a summary of a single commit.
+type Commit struct {
+	SHA          string
+	Subject      string
+	Author       string
+	RelativeDate string
+}
+
+// GetChangedFiles returns the files with staged, unstaged or untracked changes in the repository containing dir.
+func GetChangedFiles(dir string) ([]ChangedFile, error) {
 	cmd := exec.Command(
-		sh,
-		"-c",
-		`git rev-list --left-right --count origin/$(git rev-parse --abbrev-ref HEAD)...HEAD | awk '{printf("{ \"behind\": %s, \"ahead\": %s }\n", $1, $2)}'`,
+		"git",
+		"--no-optional-locks",
+		"status",
+		"--porcelain=v1",
+		"--untracked-files=all",
+		"-z",
 	)
 	cmd.Dir = dir
 	output, err := cmd.Output()
 	if err != nil {
 		return nil, err
 	}
-	var status BranchSyncStatus
-	err = json.Unmarshal(output, &status)
+	return parseStatusPorcelainV1(string(output)), nil
+}
+
+// parseStatusPorcelainV1 parses the NUL-terminated output of git status --porcelain=v1 -z.
+func parseStatusPorcelainV1(output string) []ChangedFile {
+	var files []ChangedFile
+	entries := strings.Split(output, "\x00")
+	for i := 0; i < len(entries); i++ {
+		entry := entries[i]
+		if len(entry) < 4 {
+			continue
+		}
+		file := ChangedFile{Status: entry[:2], Path: entry[3:]}
+		// Renames and copies are followed by a separate entry holding the original path.
+		if file.Status[0] == 'R' || file.Status[0] == 'C' {
+			i++
+		}
+		files = append(files, file)
+	}
+	return files
+}
+
+// GetRecentCommits returns up to limit commits reachable from HEAD, newest first.
+func GetRecentCommits(dir string, limit int) ([]Commit, error) {
+	cmd := exec.Command(
+		"git",
+		"log",
+		fmt.Sprintf("--max-count=%d", limit),
+		"--format=%h%x1f%s%x1f%an%x1f%ar",
+	)
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+	var commits []Commit
+	for line := range strings.SplitSeq(strings.TrimSpace(string(output)), "\n") {
+		fields := strings.Split(line, "\x1f")
+		if len(fields) != 4 {
+			continue
+		}
+		commits = append(commits, Commit{
+			SHA:          fields[0],
+			Subject:      fields[1],
+			Author:       fields[2],
+			RelativeDate: fields[3],
+		})
+	}
+	return commits, nil
+}
+
+// GetLocalBranches returns the local branch names, most recently committed first.
+func GetLocalBranches(dir string) ([]string, error) {
+	cmd := exec.Command(
+		"git",
+		"for-each-ref",
+		"--sort=-committerdate",
+		"--format=%(refname:short)",
+		"refs/heads",
+	)
+	cmd.Dir = dir
+	output, err := cmd.Output()
 	if err != nil {
 		return nil, err
 	}
-	return &status, nil
+	return strings.Fields(string(output)), nil
 }
@@ -0,0 +1,123 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// GitFetcher periodically runs git fetch once per unique repository root in the background.
+type GitFetcher struct {
+	interval time.Duration
+	env      []string
+	onStart  func(dirs []string)
+	onDone   func(dirs []string, err error)
+
+	// roots maps each repository root to the directories inside it.
+	roots  map[string][]string
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewGitFetcher creates a fetcher for the repositories containing the given directories.
+// Directories outside a git repository are ignored. env is the environment git runs with; nil
+// uses the current process environment. onStart and onDone receive the directories
+// of the repository being fetched and are called from a background goroutine.
+func NewGitFetcher(
+	dirs []string,
+	interval time.Duration,
+	env []string,
+	onStart func(dirs []string),
+	onDone func(dirs []string, err error),
+) *GitFetcher {
+	roots := make(map[string][]string)
+	for _, dir := range dirs {
+		root, err := GetRepositoryRoot(dir)
+		if err != nil {
+			continue
+		}
+		roots[root] = append(roots[root], dir)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &GitFetcher{
+		interval: interval,
+		env:      env,
+		onStart:  onStart,
+		onDone:   onDone,
+		roots:    roots,
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+}
+
+// Roots returns the repository roots being fetched.
+func (f *GitFetcher) Roots() []string {
+	roots := make([]string, 0, len(f.roots))
+	for root := range f.roots {
+		roots = append(roots, root)
+	}
+	return roots
+}
+
+// Start begins fetching every repository on the configured interval.
+func (f *GitFetcher) Start() {
+	for root, dirs := range f.roots {
+		f.wg.Go(func() { f.loop(root, dirs) })
+	}
+}
+
+// Stop stops all background fetches, killing any git fetch still running, and waits for them to
+// return. It is safe to call more than once.
+func (f *GitFetcher) Stop() {
+	f.cancel()
+	f.wg.Wait()
+}
+
+func (f *GitFetcher) loop(root string, dirs []string) {
+	ticker := time.NewTicker(f.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-f.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if f.onStart != nil {
+			f.onStart(dirs)
+		}
+		err := f.Fetch(root)
+		if f.ctx.Err() != nil {
+			return
+		}
+		if f.onDone != nil {
+			f.onDone(dirs, err)
+		}
+	}
+}
+
+// Fetch runs git fetch for every remote of the repository at root. The fetch is killed when the
+// fetcher is stopped.
+func (f *GitFetcher) Fetch(root string) error {
+	cmd := exec.CommandContext(f.ctx, "git", "fetch", "--all", "--prune", "--quiet")
+	cmd.Dir = root
+	env := f.env
+	if env == nil {
+		env = os.Environ()
+	}
+	// Fail instead of waiting for credentials that cannot be entered in the background.
+	cmd.Env = append(slices.Clip(env), "GIT_TERMINAL_PROMPT=0")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		if message := strings.TrimSpace(string(output)); message != "" {
+			return fmt.Errorf("%w: %s", err, message)
+		}
+		return err
+	}
+	return nil
+}
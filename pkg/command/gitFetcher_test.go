@@ -0,0 +1,100 @@
+package command
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jiyeol-lee/localdev/pkg/internal/gittest"
+)
+
+func TestNewGitFetcher_GroupsDirsByRepositoryRoot(t *testing.T) {
+	repo := gittest.NewRepo(t)
+	api := filepath.Join(repo, "api")
+	web := filepath.Join(repo, "web")
+	for _, dir := range []string{api, web} {
+		if err := os.Mkdir(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	f := NewGitFetcher([]string{api, web, t.TempDir()}, time.Hour, nil, nil, nil)
+	if got, want := f.Roots(), []string{repo}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Roots() = %v, want %v", got, want)
+	}
+	if got, want := f.roots[repo], []string{api, web}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("dirs for %s = %v, want %v", repo, got, want)
+	}
+}
+
+func TestGitFetcher_FetchUpdatesBehindCount(t *testing.T) {
+	repo, remote := gittest.NewRepoWithRemote(t)
+	other := filepath.Join(t.TempDir(), "other")
+	gittest.Run(t, repo, "clone", "-q", remote, other)
+	gittest.Run(t, other, "commit", "-q", "--allow-empty", "-m", "remote change")
+	gittest.Run(t, other, "push", "-q", "origin", "main")
+
+	started := make(chan []string, 1)
+	done := make(chan error, 1)
+	f := NewGitFetcher(
+		[]string{repo},
+		20*time.Millisecond,
+		nil,
+		func(dirs []string) {
+			select {
+			case started <- dirs:
+			default:
+			}
+		},
+		func(_ []string, err error) {
+			select {
+			case done <- err:
+			default:
+			}
+		},
+	)
+	f.Start()
+	t.Cleanup(f.Stop)
+
+	select {
+	case dirs := <-started:
+		if !reflect.DeepEqual(dirs, []string{repo}) {
+			t.Fatalf("onStart dirs = %v, want %v", dirs, []string{repo})
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the fetch to start")
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("fetch error = %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("timed out waiting for the fetch to finish")
+	}
+
+	status, err := GetBranchSyncStatus(repo)
+	if err != nil {
+		t.Fatalf("GetBranchSyncStatus() error = %v", err)
+	}
+	if status.Behind != 1 {
+		t.Fatalf("behind = %d, want 1 after fetch", status.Behind)
+	}
+}
+
+func TestGitFetcher_FetchReportsFailure(t *testing.T) {
+	repo := gittest.NewRepo(t)
+	gittest.Run(t, repo, "remote", "add", "origin", filepath.Join(t.TempDir(), "missing.git"))
+
+	f := NewGitFetcher([]string{repo}, time.Hour, nil, nil, nil)
+	err := f.Fetch(repo)
+	if err == nil {
+		t.Fatal("Fetch() expected error for a missing remote")
+	}
+	if !strings.Contains(err.Error(), "missing.git") {
+		t.Errorf("Fetch() error = %v, want git's message included", err)
+	}
+}
@@ -0,0 +1,152 @@
+package command
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// gitWatchInterval is how often HEAD and refs are checked for changes between full refreshes.
+var gitWatchInterval = time.Second
+
+// GitStatusPoller keeps a cache of git status per directory, refreshed in the background on an
+// interval and whenever the repository's HEAD or refs change.
+type GitStatusPoller struct {
+	interval time.Duration
+	onChange func(dir string)
+
+	mu       sync.Mutex
+	dirs     []string
+	statuses map[string]GitStatus
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewGitStatusPoller creates a poller for the given directories. onChange is called from a
+// background goroutine whenever the cached status of a directory changes.
+func NewGitStatusPoller(
+	dirs []string,
+	interval time.Duration,
+	onChange func(dir string),
+) *GitStatusPoller {
+	unique := make([]string, 0, len(dirs))
+	seen := make(map[string]bool)
+	for _, dir := range dirs {
+		if !seen[dir] {
+			seen[dir] = true
+			unique = append(unique, dir)
+		}
+	}
+	return &GitStatusPoller{
+		interval: interval,
+		onChange: onChange,
+		dirs:     unique,
+		statuses: make(map[string]GitStatus),
+		done:     make(chan struct{}),
+	}
+}
+
+// Start begins watching every directory in its own goroutine.
+func (p *GitStatusPoller) Start() {
+	for _, dir := range p.dirs {
+		go p.watch(dir)
+	}
+}
+
+// Stop stops all background watchers. It is safe to call more than once.
+func (p *GitStatusPoller) Stop() {
+	p.stopOnce.Do(func() {
+		close(p.done)
+	})
+}
+
+// Get returns the cached status of dir and whether it has been collected yet.
+func (p *GitStatusPoller) Get(dir string) (GitStatus, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	status, ok := p.statuses[dir]
+	return status, ok
+}
+
+// Refresh collects the status of dir immediately, notifying onChange if it changed.
+func (p *GitStatusPoller) Refresh(dir string) {
+	status := ReadGitStatus(dir)
+
+	p.mu.Lock()
+	old, had := p.statuses[dir]
+	p.statuses[dir] = status
+	p.mu.Unlock()
+
+	if (!had || !reflect.DeepEqual(old, status)) && p.onChange != nil {
+		p.onChange(dir)
+	}
+}
+
+func (p *GitStatusPoller) watch(dir string) {
+	ticker := time.NewTicker(gitWatchInterval)
+	defer ticker.Stop()
+
+	lastFingerprint := ""
+	var lastRefresh time.Time
+	for {
+		fingerprint := gitRefsFingerprint(dir)
+		if lastRefresh.IsZero() || fingerprint != lastFingerprint ||
+			time.Since(lastRefresh) >= p.interval {
+			p.Refresh(dir)
+			lastFingerprint = fingerprint
+			lastRefresh = time.Now()
+		}
+
+		select {
+		case <-p.done:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// gitRefsFingerprint summarizes the modification state of HEAD, the index, refs and in-progress
+// operation markers of the repository containing dir, so changes can be detected without running git.
+func gitRefsFingerprint(dir string) string {
+	gitDir, commonDir, err := findGitDir(dir)
+	if err != nil {
+		return ""
+	}
+
+	var b strings.Builder
+	stat := func(path string) {
+		if info, err := os.Stat(path); err == nil {
+			fmt.Fprintf(&b, "%s:%d:%d;", path, info.ModTime().UnixNano(), info.Size())
+		}
+	}
+	for _, name := range []string{
+		"HEAD",
+		"index",
+		"MERGE_HEAD",
+		"CHERRY_PICK_HEAD",
+		"REVERT_HEAD",
+		"rebase-merge",
+		"rebase-apply",
+	} {
+		stat(filepath.Join(gitDir, name))
+	}
+	stat(filepath.Join(commonDir, "packed-refs"))
+	stat(filepath.Join(commonDir, "refs", "stash"))
+	for _, refsDir := range []string{"refs/heads", "refs/remotes"} {
+		_ = filepath.WalkDir(
+			filepath.Join(commonDir, refsDir),
+			func(path string, d fs.DirEntry, err error) error {
+				if err == nil && !d.IsDir() {
+					stat(path)
+				}
+				return nil
+			},
+		)
+	}
+	return b.String()
+}
@@ -0,0 +1,52 @@
+package command
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jiyeol-lee/localdev/pkg/internal/gittest"
+)
+
+func TestGitStatusPoller_RefreshesWhenHeadChanges(t *testing.T) {
+	repo := gittest.NewRepo(t)
+	nonRepo := t.TempDir()
+
+	oldInterval := gitWatchInterval
+	gitWatchInterval = 20 * time.Millisecond
+	t.Cleanup(func() { gitWatchInterval = oldInterval })
+
+	changed := make(chan string, 10)
+	p := NewGitStatusPoller([]string{repo, nonRepo, repo}, time.Hour, func(dir string) {
+		changed <- dir
+	})
+	p.Start()
+	t.Cleanup(p.Stop)
+
+	waitForChange := func(want string) {
+		t.Helper()
+		for {
+			select {
+			case dir := <-changed:
+				if dir == want {
+					return
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatalf("timed out waiting for a status change in %s", want)
+			}
+		}
+	}
+
+	waitForChange(repo)
+	if status, ok := p.Get(repo); !ok || !status.IsRepo || status.Branch != "main" {
+		t.Fatalf("Get(repo) = %+v, %v; want main branch", status, ok)
+	}
+	if status, ok := p.Get(nonRepo); ok && status.IsRepo {
+		t.Fatalf("Get(nonRepo) = %+v; want no repository", status)
+	}
+
+	gittest.Run(t, repo, "checkout", "-q", "-b", "feature")
+	waitForChange(repo)
+	if status, _ := p.Get(repo); status.Branch != "feature" {
+		t.Fatalf("Get(repo).Branch = %q, want %q", status.Branch, "feature")
+	}
+}
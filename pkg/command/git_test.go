@@ -0,0 +1,295 @@
+package command
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/jiyeol-lee/localdev/pkg/internal/gittest"
+)
+
+func TestGetCurrentBranch(t *testing.T) {
+	t.Run("branch from subdirectory", func(t *testing.T) {
+		repo := gittest.NewRepo(t)
+		gittest.Run(t, repo, "checkout", "-q", "-b", "feature/x")
+		sub := filepath.Join(repo, "sub")
+		if err := os.Mkdir(sub, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		got, err := GetCurrentBranch(sub)
+		if err != nil {
+			t.Fatalf("GetCurrentBranch() error = %v", err)
+		}
+		if got != "feature/x" {
+			t.Errorf("GetCurrentBranch() = %q, want %q", got, "feature/x")
+		}
+	})
+
+	t.Run("detached HEAD", func(t *testing.T) {
+		repo := gittest.NewRepo(t)
+		gittest.Run(t, repo, "checkout", "-q", "--detach")
+		got, err := GetCurrentBranch(repo)
+		if err != nil {
+			t.Fatalf("GetCurrentBranch() error = %v", err)
+		}
+		if got != "" {
+			t.Errorf("GetCurrentBranch() = %q, want empty", got)
+		}
+	})
+
+	t.Run("linked worktree", func(t *testing.T) {
+		repo := gittest.NewRepo(t)
+		worktree := filepath.Join(t.TempDir(), "wt")
+		gittest.Run(t, repo, "worktree", "add", "-q", "-b", "wt-branch", worktree)
+		got, err := GetCurrentBranch(worktree)
+		if err != nil {
+			t.Fatalf("GetCurrentBranch() error = %v", err)
+		}
+		if got != "wt-branch" {
+			t.Errorf("GetCurrentBranch() = %q, want %q", got, "wt-branch")
+		}
+	})
+
+	t.Run("not a repository", func(t *testing.T) {
+		if _, err := GetCurrentBranch(t.TempDir()); err == nil {
+			t.Error("GetCurrentBranch() expected error outside a repository")
+		}
+	})
+}
+
+func TestGetBranchSyncStatus(t *testing.T) {
+	repo, remote := gittest.NewRepoWithRemote(t)
+	gittest.Run(t, repo, "commit", "-q", "--allow-empty", "-m", "local")
+
+	other := filepath.Join(t.TempDir(), "other")
+	gittest.Run(t, repo, "clone", "-q", remote, other)
+	gittest.Run(t, other, "commit", "-q", "--allow-empty", "-m", "remote 1")
+	gittest.Run(t, other, "commit", "-q", "--allow-empty", "-m", "remote 2")
+	gittest.Run(t, other, "push", "-q", "origin", "main")
+	gittest.Run(t, repo, "fetch", "-q")
+
+	got, err := GetBranchSyncStatus(repo)
+	if err != nil {
+		t.Fatalf("GetBranchSyncStatus() error = %v", err)
+	}
+	if want := (&BranchSyncStatus{Behind: 2, Ahead: 1}); !reflect.DeepEqual(got, want) {
+		t.Errorf("GetBranchSyncStatus() = %+v, want %+v", got, want)
+	}
+}
+
+func Test_parseLeftRightCount(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		want    *BranchSyncStatus
+		wantErr bool
+	}{
+		{name: "tab separated", output: "3\t1\n", want: &BranchSyncStatus{Behind: 3, Ahead: 1}},
+		{name: "missing field", output: "3\n", wantErr: true},
+		{name: "not a number", output: "x\t1\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseLeftRightCount(tt.output)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseLeftRightCount() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseLeftRightCount() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetBranchSyncStatus_UsesConfiguredUpstream(t *testing.T) {
+	repo := gittest.NewRepo(t)
+	remote := filepath.Join(t.TempDir(), "fork.git")
+	gittest.Run(t, repo, "clone", "-q", "--bare", repo, remote)
+	gittest.Run(t, repo, "remote", "add", "fork", remote)
+	gittest.Run(t, repo, "fetch", "-q", "fork")
+	gittest.Run(t, repo, "branch", "-q", "--set-upstream-to=fork/main")
+	gittest.Run(t, repo, "commit", "-q", "--allow-empty", "-m", "local")
+
+	got, err := GetBranchSyncStatus(repo)
+	if err != nil {
+		t.Fatalf("GetBranchSyncStatus() error = %v", err)
+	}
+	if want := (&BranchSyncStatus{Behind: 0, Ahead: 1}); !reflect.DeepEqual(got, want) {
+		t.Errorf("GetBranchSyncStatus() = %+v, want %+v", got, want)
+	}
+}
+
+func TestReadGitStatus(t *testing.T) {
+	t.Run("not a repository", func(t *testing.T) {
+		if got := ReadGitStatus(t.TempDir()); !reflect.DeepEqual(got, GitStatus{}) {
+			t.Errorf("ReadGitStatus() = %+v, want zero value", got)
+		}
+	})
+
+	t.Run("changes, stash and upstream", func(t *testing.T) {
+		repo, _ := gittest.NewRepoWithRemote(t)
+		write := func(name, content string) {
+			t.Helper()
+			if err := os.WriteFile(filepath.Join(repo, name), []byte(content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		write("tracked.txt", "v1")
+		write("modified.txt", "v1")
+		gittest.Run(t, repo, "add", ".")
+		gittest.Run(t, repo, "commit", "-q", "-m", "add files")
+		write("stashed.txt", "v1")
+		gittest.Run(t, repo, "add", "stashed.txt")
+		gittest.Run(t, repo, "stash", "-q")
+		write("tracked.txt", "v2")
+		gittest.Run(t, repo, "add", "tracked.txt")
+		write("modified.txt", "v2")
+		write("untracked.txt", "v1")
+
+		got := ReadGitStatus(repo)
+		want := GitStatus{
+			IsRepo:    true,
+			Branch:    "main",
+			HeadSHA:   gittest.Run(t, repo, "rev-parse", "--short=7", "HEAD"),
+			Upstream:  "origin/main",
+			Sync:      &BranchSyncStatus{Ahead: 1, Behind: 0},
+			Staged:    1,
+			Unstaged:  1,
+			Untracked: 1,
+			Stashes:   1,
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("ReadGitStatus() = %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("detached HEAD without upstream", func(t *testing.T) {
+		repo := gittest.NewRepo(t)
+		gittest.Run(t, repo, "checkout", "-q", "--detach")
+		got := ReadGitStatus(repo)
+		if got.Branch != "" || got.HeadSHA == "" || got.Upstream != "" || got.Sync != nil {
+			t.Errorf("ReadGitStatus() = %+v, want detached HEAD with short SHA and no upstream", got)
+		}
+	})
+
+	t.Run("merge in progress", func(t *testing.T) {
+		repo := gittest.NewRepo(t)
+		file := filepath.Join(repo, "conflict.txt")
+		if err := os.WriteFile(file, []byte("base\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		gittest.Run(t, repo, "add", ".")
+		gittest.Run(t, repo, "commit", "-q", "-m", "base")
+		gittest.Run(t, repo, "checkout", "-q", "-b", "other")
+		if err := os.WriteFile(file, []byte("other\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		gittest.Run(t, repo, "commit", "-q", "-am", "other")
+		gittest.Run(t, repo, "checkout", "-q", "main")
+		if err := os.WriteFile(file, []byte("main\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		gittest.Run(t, repo, "commit", "-q", "-am", "main")
+		// The merge is expected to stop with a conflict.
+		_ = gittest.Command(repo, "merge", "-q", "other").Run()
+
+		got := ReadGitStatus(repo)
+		if got.Operation != "merge" || got.Conflicted != 1 {
+			t.Errorf("ReadGitStatus() = %+v, want merge in progress with 1 conflict", got)
+		}
+	})
+}
+
+func Test_parseStatusPorcelainV2(t *testing.T) {
+	output := strings.Join([]string{
+		"# branch.oid 1234567890abcdef1234567890abcdef12345678",
+		"# branch.head feature",
+		"# branch.upstream upstream/feature",
+		"# branch.ab +2 -3",
+		"1 M. N... 100644 100644 100644 abc abc staged.go",
+		"1 .M N... 100644 100644 100644 abc abc unstaged.go",
+		"1 MM N... 100644 100644 100644 abc abc both.go",
+		"2 R. N... 100644 100644 100644 abc abc R100 new.go\told.go",
+		"u UU N... 100644 100644 100644 100644 abc abc abc conflict.go",
+		"? untracked.go",
+		"",
+	}, "\n")
+
+	var got GitStatus
+	parseStatusPorcelainV2(output, &got)
+	want := GitStatus{
+		Branch:     "feature",
+		HeadSHA:    "1234567",
+		Upstream:   "upstream/feature",
+		Sync:       &BranchSyncStatus{Ahead: 2, Behind: 3},
+		Staged:     3,
+		Unstaged:   2,
+		Untracked:  1,
+		Conflicted: 1,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseStatusPorcelainV2() = %+v, want %+v", got, want)
+	}
+
+	var initial GitStatus
+	parseStatusPorcelainV2("# branch.oid (initial)\n# branch.head (detached)\n", &initial)
+	if initial.HeadSHA != "" || initial.Branch != "" {
+		t.Errorf("parseStatusPorcelainV2() = %+v, want empty SHA and branch", initial)
+	}
+}
+
+func TestGetChangedFilesCommitsAndBranches(t *testing.T) {
+	repo := gittest.NewRepo(t)
+	if err := os.WriteFile(filepath.Join(repo, "a.txt"), []byte("a"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	gittest.Run(t, repo, "add", "a.txt")
+	gittest.Run(t, repo, "commit", "-q", "-m", "add a")
+	gittest.Run(t, repo, "branch", "older")
+	gittest.Run(t, repo, "mv", "a.txt", "b.txt")
+	if err := os.WriteFile(filepath.Join(repo, "new file.txt"), []byte("n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	changes, err := GetChangedFiles(repo)
+	if err != nil {
+		t.Fatalf("GetChangedFiles() error = %v", err)
+	}
+	wantChanges := []ChangedFile{{Status: "R ", Path: "b.txt"}, {Status: "??", Path: "new file.txt"}}
+	if !reflect.DeepEqual(changes, wantChanges) {
+		t.Errorf("GetChangedFiles() = %+v, want %+v", changes, wantChanges)
+	}
+
+	commits, err := GetRecentCommits(repo, 1)
+	if err != nil {
+		t.Fatalf("GetRecentCommits() error = %v", err)
+	}
+	if len(commits) != 1 || commits[0].Subject != "add a" || commits[0].Author != "test" ||
+		commits[0].SHA == "" {
+		t.Errorf("GetRecentCommits() = %+v, want the latest commit only", commits)
+	}
+
+	branches, err := GetLocalBranches(repo)
+	if err != nil {
+		t.Fatalf("GetLocalBranches() error = %v", err)
+	}
+	slices.Sort(branches)
+	if want := []string{"main", "older"}; !reflect.DeepEqual(branches, want) {
+		t.Errorf("GetLocalBranches() = %v, want %v", branches, want)
+	}
+}
+
+func Test_parseStatusPorcelainV1(t *testing.T) {
+	output := " M modified.go\x00R  new.go\x00old.go\x00?? dir/untracked.go\x00"
+	want := []ChangedFile{
+		{Status: " M", Path: "modified.go"},
+		{Status: "R ", Path: "new.go"},
+		{Status: "??", Path: "dir/untracked.go"},
+	}
+	if got := parseStatusPorcelainV1(output); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseStatusPorcelainV1() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,128 @@
+package command
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// processPollInterval is how often a signaled process group is checked for exit.
+var processPollInterval = 100 * time.Millisecond
+
+// killTimeout is how long to wait for a process group to disappear after SIGKILL.
+const killTimeout = 2 * time.Second
+
+// StopStep is one step of a stop sequence: a signal sent to a process group and how long to
+// wait for the group to exit before the next step.
+type StopStep struct {
+	Signal  unix.Signal
+	Timeout time.Duration
+}
+
+// ProcessGroupAlive reports whether any process in the process group pgid is still running.
+func ProcessGroupAlive(pgid int) bool {
+	return !errors.Is(unix.Kill(-pgid, 0), syscall.ESRCH)
+}
+
+// clockTicks is the unit of the times in /proc, USER_HZ, which Linux fixes at 100 per second.
+const clockTicks = 100
+
+// procDir is the mount point of the proc file system.
+var procDir = "/proc"
+
+// ProcessStartTime returns when the process pid started. It reads the proc file system, so it fails
+// on systems without one such as macOS, and returns an error wrapping fs.ErrNotExist when pid is not
+// running.
+func ProcessStartTime(pid int) (time.Time, error) {
+	stat, err := os.ReadFile(filepath.Join(procDir, strconv.Itoa(pid), "stat"))
+	if err != nil {
+		return time.Time{}, err
+	}
+	// The command name in the second field may contain spaces and parentheses, so the fields
+	// are counted from the last closing parenthesis, which is followed by field 3.
+	end := bytes.LastIndexByte(stat, ')')
+	if end < 0 {
+		return time.Time{}, fmt.Errorf("malformed stat of process %d", pid)
+	}
+	fields := strings.Fields(string(stat[end+1:]))
+	const startTimeField = 22 - 3
+	if len(fields) <= startTimeField {
+		return time.Time{}, fmt.Errorf("malformed stat of process %d", pid)
+	}
+	ticks, err := strconv.ParseInt(fields[startTimeField], 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("malformed start time of process %d: %w", pid, err)
+	}
+	bootTime, err := readBootTime()
+	if err != nil {
+		return time.Time{}, err
+	}
+	return bootTime.Add(time.Duration(ticks) * time.Second / clockTicks), nil
+}
+
+// readBootTime returns the system boot time from the btime line of /proc/stat.
+func readBootTime() (time.Time, error) {
+	stat, err := os.ReadFile(filepath.Join(procDir, "stat"))
+	if err != nil {
+		return time.Time{}, err
+	}
+	for line := range strings.SplitSeq(string(stat), "\n") {
+		if value, ok := strings.CutPrefix(line, "btime "); ok {
+			seconds, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+			if err != nil {
+				return time.Time{}, fmt.Errorf("malformed boot time: %w", err)
+			}
+			return time.Unix(seconds, 0), nil
+		}
+	}
+	return time.Time{}, errors.New("boot time not found")
+}
+
+// TerminateProcessGroup runs steps in order until the process group pgid has exited, ending with
+// SIGKILL when the group survives every step. onStep, if not nil, is called before each signal is
+// sent. It reports whether the group exited, or an error when a signal could not be sent.
+func TerminateProcessGroup(pgid int, steps []StopStep, onStep func(step StopStep)) (bool, error) {
+	if len(steps) == 0 || steps[len(steps)-1].Signal != unix.SIGKILL {
+		steps = append(steps[:len(steps):len(steps)], StopStep{Signal: unix.SIGKILL, Timeout: killTimeout})
+	}
+	for _, step := range steps {
+		if !ProcessGroupAlive(pgid) {
+			return true, nil
+		}
+		if onStep != nil {
+			onStep(step)
+		}
+		if err := unix.Kill(-pgid, step.Signal); err != nil {
+			if errors.Is(err, syscall.ESRCH) {
+				return true, nil
+			}
+			return false, fmt.Errorf("error sending %s to process group %d: %w", unix.SignalName(step.Signal), pgid, err)
+		}
+		if waitProcessGroupExit(pgid, step.Timeout) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// waitProcessGroupExit waits up to timeout for the process group pgid to exit.
+func waitProcessGroupExit(pgid int, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for {
+		if !ProcessGroupAlive(pgid) {
+			return true
+		}
+		if !time.Now().Before(deadline) {
+			return false
+		}
+		time.Sleep(processPollInterval)
+	}
+}
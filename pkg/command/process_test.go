@@ -0,0 +1,147 @@
+package command
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// startProcessGroup starts script in its own process group and reaps it in the background.
+func startProcessGroup(t *testing.T, script string) int {
+	t.Helper()
+	cmd := exec.Command("sh", "-c", script)
+	cmd.SysProcAttr = &unix.SysProcAttr{Setpgid: true}
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	go func() { _ = cmd.Wait() }()
+	t.Cleanup(func() { _ = unix.Kill(-cmd.Process.Pid, unix.SIGKILL) })
+	return cmd.Process.Pid
+}
+
+func TestTerminateProcessGroup(t *testing.T) {
+	tests := []struct {
+		name        string
+		script      string
+		steps       []StopStep
+		wantSignals []unix.Signal
+	}{
+		{
+			name:        "exits on the first signal",
+			script:      "exec sleep 30",
+			steps:       []StopStep{{Signal: unix.SIGTERM, Timeout: 5 * time.Second}},
+			wantSignals: []unix.Signal{unix.SIGTERM},
+		},
+		{
+			name:   "escalates through the steps and ends with SIGKILL",
+			script: "trap '' TERM INT; sleep 30",
+			steps: []StopStep{
+				{Signal: unix.SIGINT, Timeout: 200 * time.Millisecond},
+				{Signal: unix.SIGTERM, Timeout: 200 * time.Millisecond},
+			},
+			wantSignals: []unix.Signal{unix.SIGINT, unix.SIGTERM, unix.SIGKILL},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pgid := startProcessGroup(t, tt.script)
+			// Give the shell time to install its traps.
+			time.Sleep(100 * time.Millisecond)
+
+			var signals []unix.Signal
+			exited, err := TerminateProcessGroup(pgid, tt.steps, func(step StopStep) {
+				signals = append(signals, step.Signal)
+			})
+			if err != nil {
+				t.Fatalf("TerminateProcessGroup() error = %v", err)
+			}
+			if !exited {
+				t.Fatal("TerminateProcessGroup() = false, want the process group to exit")
+			}
+			if len(signals) != len(tt.wantSignals) {
+				t.Fatalf("signals = %v, want %v", signals, tt.wantSignals)
+			}
+			for i := range signals {
+				if signals[i] != tt.wantSignals[i] {
+					t.Fatalf("signals = %v, want %v", signals, tt.wantSignals)
+				}
+			}
+			if ProcessGroupAlive(pgid) {
+				t.Error("ProcessGroupAlive() = true after termination")
+			}
+		})
+	}
+}
+
+func TestTerminateProcessGroup_AlreadyExited(t *testing.T) {
+	cmd := exec.Command("true")
+	cmd.SysProcAttr = &unix.SysProcAttr{Setpgid: true}
+	if err := cmd.Run(); err != nil {
+		t.Fatal(err)
+	}
+	called := false
+	exited, err := TerminateProcessGroup(cmd.Process.Pid, nil, func(StopStep) { called = true })
+	if err != nil || !exited || called {
+		t.Fatalf("TerminateProcessGroup() = %v, %v (signaled %v), want true, nil without signals", exited, err, called)
+	}
+}
+
+func TestProcessStartTime(t *testing.T) {
+	dir := t.TempDir()
+	procDir = dir
+	t.Cleanup(func() { procDir = "/proc" })
+	write := func(name, content string) {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("stat", "cpu  1 2 3\nbtime 1709647629\nprocesses 42\n")
+	// The command name contains a space and a closing parenthesis; field 22 is 12345 ticks.
+	write("42/stat", "42 (my (app)) S 1 42 42 0 -1 4194560 1 0 0 0 0 0 0 0 20 0 1 0 12345 1000 10\n")
+	write("43/stat", "43 (short) S 1 43\n")
+
+	got, err := ProcessStartTime(42)
+	if err != nil {
+		t.Fatalf("ProcessStartTime() error = %v", err)
+	}
+	if want := time.Unix(1709647629, 0).Add(123450 * time.Millisecond); !got.Equal(want) {
+		t.Errorf("ProcessStartTime() = %v, want %v", got, want)
+	}
+	if _, err := ProcessStartTime(43); err == nil {
+		t.Error("ProcessStartTime() with a malformed stat returned no error")
+	}
+	if _, err := ProcessStartTime(44); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("ProcessStartTime() of a missing process error = %v, want fs.ErrNotExist", err)
+	}
+}
+
+func TestProcessStartTime_ChildProcess(t *testing.T) {
+	if _, err := os.Stat("/proc/self/stat"); err != nil {
+		t.Skip("no proc file system")
+	}
+	cmd := exec.Command("sleep", "30")
+	before := time.Now()
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = cmd.Process.Kill(); _ = cmd.Wait() })
+
+	got, err := ProcessStartTime(cmd.Process.Pid)
+	if err != nil {
+		t.Fatalf("ProcessStartTime() error = %v", err)
+	}
+	// The boot time is only known to the second.
+	if got.Before(before.Add(-2*time.Second)) || got.After(time.Now().Add(2*time.Second)) {
+		t.Errorf("ProcessStartTime() = %v, want about %v", got, before)
+	}
+}
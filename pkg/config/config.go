@@ -2,11 +2,21 @@ package config
 
 import (
 	"fmt"
+	"maps"
 	"os"
+	"path"
 	"path/filepath"
+	"regexp"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/goccy/go-yaml"
+	"github.com/jiyeol-lee/localdev/pkg/constant"
+	"github.com/jiyeol-lee/localdev/pkg/internal/cron"
+	"github.com/jiyeol-lee/localdev/pkg/internal/placeholder"
+	"github.com/jiyeol-lee/localdev/pkg/internal/signame"
+	"github.com/jiyeol-lee/localdev/pkg/util"
 )
 
 // defaultConfigFile constructs the default configuration file path using the provided configFileName.
@@ -30,6 +40,8 @@ func (c *Config) LoadConfig(configFileName string) error {
 		return err
 	}
 
+	c.path = configFile
+
 	file, err := os.ReadFile(configFile)
 	if err != nil && !os.IsNotExist(err) {
 		return err
@@ -40,11 +52,57 @@ func (c *Config) LoadConfig(configFileName string) error {
 		return err
 	}
 
-	// Configuration validation: ensure at least one pane and required fields are present
+	return c.validate()
+}
+
+// validate ensures at least one pane is configured and required fields are present.
+func (c *Config) validate() error {
 	if len(c.Panes) == 0 {
 		return fmt.Errorf("configuration must contain at least one pane")
 	}
 	var validationErrors []string
+	if c.GetGitFetchInterval() < 0 {
+		validationErrors = append(
+			validationErrors,
+			"project_settings.git.fetch_interval must not be negative",
+		)
+	}
+	if c.ProjectSettings != nil && c.ProjectSettings.Mask != nil {
+		for _, glob := range c.ProjectSettings.Mask.Env {
+			if _, err := path.Match(glob, ""); err != nil {
+				validationErrors = append(
+					validationErrors,
+					fmt.Sprintf("project_settings.mask.env has invalid pattern %q: %v", glob, err),
+				)
+			}
+		}
+		for _, pattern := range c.ProjectSettings.Mask.Patterns {
+			if _, err := regexp.Compile(pattern); err != nil {
+				validationErrors = append(
+					validationErrors,
+					fmt.Sprintf("project_settings.mask.patterns has invalid regexp %q: %v", pattern, err),
+				)
+			}
+		}
+	}
+	if order := c.GetShutdownOrder(); order != constant.ShutdownOrder.Concurrent &&
+		order != constant.ShutdownOrder.Reverse {
+		validationErrors = append(
+			validationErrors,
+			fmt.Sprintf("project_settings.shutdown_order is unsupported: %s", order),
+		)
+	}
+	paneIndexes := make(map[string]int, len(c.Panes))
+	for i, pane := range c.Panes {
+		if first, ok := paneIndexes[pane.Name]; ok && pane.Name != "" {
+			validationErrors = append(
+				validationErrors,
+				fmt.Sprintf("pane[%d] has the same name as pane[%d]: %s", i, first, pane.Name),
+			)
+			continue
+		}
+		paneIndexes[pane.Name] = i
+	}
 	for i, pane := range c.Panes {
 		if pane.Name == "" {
 			validationErrors = append(
@@ -64,12 +122,98 @@ func (c *Config) LoadConfig(configFileName string) error {
 				fmt.Sprintf("pane[%d] is missing required field: start", i),
 			)
 		}
-		if pane.Stop == "" {
+		if pane.Stop == "" && !pane.IsTask() {
 			validationErrors = append(
 				validationErrors,
 				fmt.Sprintf("pane[%d] is missing required field: stop", i),
 			)
 		}
+		if pane.Type != "" && pane.Type != constant.PaneType.Service && !pane.IsTask() {
+			validationErrors = append(
+				validationErrors,
+				fmt.Sprintf("pane[%d] has unsupported type: %s", i, pane.Type),
+			)
+		}
+		if pane.StopSignal != "" {
+			if _, err := signame.Parse(pane.StopSignal); err != nil {
+				validationErrors = append(
+					validationErrors,
+					fmt.Sprintf("pane[%d] has unknown stop_signal: %s", i, pane.StopSignal),
+				)
+			}
+		}
+		if pane.StopTimeout < 0 {
+			validationErrors = append(
+				validationErrors,
+				fmt.Sprintf("pane[%d] stop_timeout must not be negative", i),
+			)
+		}
+		for j, step := range pane.StopEscalation {
+			if _, err := signame.Parse(step.Signal); err != nil {
+				validationErrors = append(
+					validationErrors,
+					fmt.Sprintf("pane[%d].stop_escalation[%d] has unknown signal: %s", i, j, step.Signal),
+				)
+			}
+			if step.Timeout < 0 {
+				validationErrors = append(
+					validationErrors,
+					fmt.Sprintf("pane[%d].stop_escalation[%d] timeout must not be negative", i, j),
+				)
+			}
+		}
+		if pane.StopCommandTimeout < 0 {
+			validationErrors = append(
+				validationErrors,
+				fmt.Sprintf("pane[%d] stop_command_timeout must not be negative", i),
+			)
+		}
+		for _, name := range pane.DependsOn {
+			if j, ok := paneIndexes[name]; !ok || j == i {
+				validationErrors = append(
+					validationErrors,
+					fmt.Sprintf("pane[%d] depends_on has unknown pane: %s", i, name),
+				)
+			}
+		}
+		for j, scheduled := range pane.Schedule {
+			if scheduled.Command == "" {
+				validationErrors = append(
+					validationErrors,
+					fmt.Sprintf("pane[%d].schedule[%d] is missing required field: command", i, j),
+				)
+			}
+			switch {
+			case (scheduled.Cron == "") == (scheduled.Every == 0):
+				validationErrors = append(
+					validationErrors,
+					fmt.Sprintf("pane[%d].schedule[%d] must set exactly one of cron or every", i, j),
+				)
+			case scheduled.Every < 0:
+				validationErrors = append(
+					validationErrors,
+					fmt.Sprintf("pane[%d].schedule[%d] every must be positive", i, j),
+				)
+			case scheduled.Cron != "":
+				if _, err := cron.Parse(scheduled.Cron); err != nil {
+					validationErrors = append(
+						validationErrors,
+						fmt.Sprintf("pane[%d].schedule[%d] has invalid cron: %v", i, j, err),
+					)
+				}
+			}
+		}
+		validationErrors = append(validationErrors, validateCommands(i, pane.Commands)...)
+		if pane.Format != "" && pane.Format != constant.PaneOutputFormat.Text &&
+			pane.Format != constant.PaneOutputFormat.JSON {
+			validationErrors = append(
+				validationErrors,
+				fmt.Sprintf("pane[%d] has unsupported format: %s", i, pane.Format),
+			)
+		}
+	}
+	if len(validationErrors) == 0 && len(c.shutdownSequence()) != len(c.Panes) {
+		validationErrors = append(validationErrors, "panes depends_on contains a cycle")
 	}
 	if len(validationErrors) > 0 {
 		return fmt.Errorf(
@@ -81,6 +225,75 @@ func (c *Config) LoadConfig(configFileName string) error {
 	return nil
 }
 
+// stepReservedCommands are the reserved commands that can be a step of a command. The others open
+// a modal or change the layout, which makes no sense in the middle of a sequence.
+var stepReservedCommands = []string{
+	constant.ReservedCommand.StartPane,
+	constant.ReservedCommand.StopPane,
+	constant.ReservedCommand.ClearPane,
+	constant.ReservedCommand.SavePaneOutput,
+}
+
+// validateCommands checks the key-bound commands of the pane at index i.
+func validateCommands(i int, commands *ConfigCommands) []string {
+	if commands == nil {
+		return nil
+	}
+	commandsMap, err := util.YamlToMap[*ConfigCommands, *ConfigCommand](commands)
+	if err != nil {
+		return []string{fmt.Sprintf("pane[%d].commands could not be read: %v", i, err)}
+	}
+	var validationErrors []string
+	for _, key := range slices.Sorted(maps.Keys(commandsMap)) {
+		cc := commandsMap[key]
+		if cc == nil {
+			continue
+		}
+		if _, err := placeholder.Parse(append([]string{cc.Command}, cc.Steps...)...); err != nil {
+			validationErrors = append(
+				validationErrors,
+				fmt.Sprintf("pane[%d].commands.%s has invalid %v", i, key, err),
+			)
+		}
+		if len(cc.Steps) == 0 {
+			continue
+		}
+		if cc.Command != "" {
+			validationErrors = append(
+				validationErrors,
+				fmt.Sprintf("pane[%d].commands.%s must not set both command and steps", i, key),
+			)
+		}
+		for j, step := range cc.Steps {
+			switch {
+			case strings.TrimSpace(step) == "":
+				validationErrors = append(
+					validationErrors,
+					fmt.Sprintf("pane[%d].commands.%s.steps[%d] is empty", i, key, j),
+				)
+			case strings.HasPrefix(step, "<") && strings.HasSuffix(step, ">") &&
+				!slices.Contains(stepReservedCommands, step):
+				validationErrors = append(
+					validationErrors,
+					fmt.Sprintf(
+						"pane[%d].commands.%s.steps[%d] has unsupported reserved command: %s",
+						i,
+						key,
+						j,
+						step,
+					),
+				)
+			}
+		}
+	}
+	return validationErrors
+}
+
+// IsTask reports whether the pane is a task that runs to completion rather than a service.
+func (p ConfigPane) IsTask() bool {
+	return p.Type == constant.PaneType.Task
+}
+
 // GetProjectDir returns the project directory from the configuration.
 func (c *Config) GetProjectDir() string {
 	if c.ProjectSettings != nil {
@@ -89,6 +302,134 @@ func (c *Config) GetProjectDir() string {
 	return ""
 }
 
+// GetShutdownOrder returns how panes are stopped on exit, "concurrent" by default.
+func (c *Config) GetShutdownOrder() string {
+	if c.ProjectSettings != nil && c.ProjectSettings.ShutdownOrder != "" {
+		return c.ProjectSettings.ShutdownOrder
+	}
+	return constant.ShutdownOrder.Concurrent
+}
+
+// GetShutdownWaits returns, for each pane index, the indexes of the panes that must finish stopping
+// before it stops on exit. A pane waits for the panes that depend on it and, with the reverse
+// shutdown order, for the pane stopped before it.
+func (c *Config) GetShutdownWaits() [][]int {
+	waits := make([][]int, len(c.Panes))
+	if c.GetShutdownOrder() == constant.ShutdownOrder.Reverse {
+		sequence := c.shutdownSequence()
+		for k := 1; k < len(sequence); k++ {
+			waits[sequence[k]] = []int{sequence[k-1]}
+		}
+		return waits
+	}
+	for i, pane := range c.Panes {
+		for _, name := range pane.DependsOn {
+			if j := c.paneIndex(name); j >= 0 && j != i && !slices.Contains(waits[j], i) {
+				waits[j] = append(waits[j], i)
+			}
+		}
+	}
+	return waits
+}
+
+// shutdownSequence orders the pane indexes so every pane comes before the panes it depends on,
+// preferring reverse declaration order. It omits the panes on a depends_on cycle.
+func (c *Config) shutdownSequence() []int {
+	// dependents counts, for each pane, the panes depending on it that are not yet in the sequence.
+	dependents := make([]int, len(c.Panes))
+	for i, pane := range c.Panes {
+		for _, name := range slices.Compact(slices.Sorted(slices.Values(pane.DependsOn))) {
+			if j := c.paneIndex(name); j >= 0 && j != i {
+				dependents[j]++
+			}
+		}
+	}
+	var sequence []int
+	done := make([]bool, len(c.Panes))
+	for len(sequence) < len(c.Panes) {
+		next := -1
+		for i := len(c.Panes) - 1; i >= 0; i-- {
+			if !done[i] && dependents[i] == 0 {
+				next = i
+				break
+			}
+		}
+		if next < 0 {
+			break
+		}
+		done[next] = true
+		sequence = append(sequence, next)
+		for _, name := range slices.Compact(slices.Sorted(slices.Values(c.Panes[next].DependsOn))) {
+			if j := c.paneIndex(name); j >= 0 && j != next {
+				dependents[j]--
+			}
+		}
+	}
+	return sequence
+}
+
+// paneIndex returns the index of the pane with the given name, or -1.
+func (c *Config) paneIndex(name string) int {
+	return slices.IndexFunc(c.Panes, func(p ConfigPane) bool { return p.Name == name })
+}
+
+// GetPath returns the path of the file the configuration was loaded from.
+func (c *Config) GetPath() string {
+	return c.path
+}
+
+// GetOutputDir returns the directory where saved pane output is written.
+// It defaults to the "output" directory under the Local Dev cache directory. A leading "~" in the
+// setting is the home directory and a relative path resolves beneath the project dir.
+func (c *Config) GetOutputDir() (string, error) {
+	if c.ProjectSettings != nil && c.ProjectSettings.OutputDir != "" {
+		dir := c.ProjectSettings.OutputDir
+		if dir == "~" || strings.HasPrefix(dir, "~/") {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return "", err
+			}
+			dir = filepath.Join(home, dir[1:])
+		}
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(c.GetProjectDir(), dir)
+		}
+		return dir, nil
+	}
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cacheDir, "localdev", "output"), nil
+}
+
+// GetGitFetchInterval returns the interval of the periodic background git fetch, or 0 when disabled.
+func (c *Config) GetGitFetchInterval() time.Duration {
+	if c.ProjectSettings != nil && c.ProjectSettings.Git != nil {
+		return c.ProjectSettings.Git.FetchInterval
+	}
+	return 0
+}
+
+// GetProjectEnv returns the project-level env vars and env files.
+func (c *Config) GetProjectEnv() (map[string]string, []string) {
+	if c.ProjectSettings != nil {
+		return c.ProjectSettings.Env, c.ProjectSettings.EnvFile
+	}
+	return nil, nil
+}
+
+// GetMaskSettings returns the glob patterns of env var names whose values are masked, including
+// the defaults, and the regular expressions whose matches are masked.
+func (c *Config) GetMaskSettings() (envNames []string, patterns []string) {
+	envNames = slices.Clone(constant.DefaultMaskedEnvNames)
+	if c.ProjectSettings != nil && c.ProjectSettings.Mask != nil {
+		envNames = append(envNames, c.ProjectSettings.Mask.Env...)
+		patterns = c.ProjectSettings.Mask.Patterns
+	}
+	return envNames, patterns
+}
+
 // GetProjectCommand returns the project command from the configuration.
 func (c *Config) GetProjectCommand() string {
 	if c.ProjectSettings != nil {
@@ -1,10 +1,12 @@
 package config
 
 import (
-	"fmt"
 	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 )
 
 func Test_defaultConfigFile(t *testing.T) {
@@ -127,43 +129,422 @@ func Test_ConfigValidation(t *testing.T) {
 	})
 }
 
-// Helper for testing validation logic directly
-func (c *Config) LoadConfigFromStruct() error {
-	if len(c.Panes) == 0 {
-		return fmt.Errorf("configuration must contain at least one pane")
-	}
-	var validationErrors []string
-	for i, pane := range c.Panes {
-		if pane.Name == "" {
-			validationErrors = append(
-				validationErrors,
-				fmt.Sprintf("pane[%d] is missing required field: name", i),
-			)
-		}
-		if pane.Dir == "" {
-			validationErrors = append(
-				validationErrors,
-				fmt.Sprintf("pane[%d] is missing required field: dir", i),
-			)
-		}
-		if pane.Start == "" {
-			validationErrors = append(
-				validationErrors,
-				fmt.Sprintf("pane[%d] is missing required field: start", i),
-			)
-		}
-		if pane.Stop == "" {
-			validationErrors = append(
-				validationErrors,
-				fmt.Sprintf("pane[%d] is missing required field: stop", i),
-			)
+func Test_ConfigValidation_Format(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		wantErr bool
+	}{
+		{name: "empty format", format: "", wantErr: false},
+		{name: "text format", format: "text", wantErr: false},
+		{name: "json format", format: "json", wantErr: false},
+		{name: "unsupported format", format: "xml", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{
+				Panes: []ConfigPane{
+					{Name: "pane1", Dir: "/tmp", Start: "echo start", Stop: "echo stop", Format: tt.format},
+				},
+			}
+			err := cfg.LoadConfigFromStruct()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadConfigFromStruct() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !strings.Contains(err.Error(), "pane[0] has unsupported format: xml") {
+				t.Errorf("unexpected error message: %v", err)
+			}
+		})
+	}
+}
+
+func Test_ConfigValidation_GitFetchInterval(t *testing.T) {
+	cfg := &Config{
+		ProjectSettings: &ProjectSettings{Git: &GitSettings{FetchInterval: -time.Minute}},
+		Panes: []ConfigPane{
+			{Name: "pane1", Dir: "/tmp", Start: "echo start", Stop: "echo stop"},
+		},
+	}
+	err := cfg.LoadConfigFromStruct()
+	if err == nil || !strings.Contains(err.Error(), "fetch_interval must not be negative") {
+		t.Fatalf("expected negative fetch interval error, got %v", err)
+	}
+
+	cfg.ProjectSettings.Git.FetchInterval = 5 * time.Minute
+	if err := cfg.LoadConfigFromStruct(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if got := cfg.GetGitFetchInterval(); got != 5*time.Minute {
+		t.Errorf("GetGitFetchInterval() = %v, want %v", got, 5*time.Minute)
+	}
+}
+
+func Test_ConfigValidation_Mask(t *testing.T) {
+	cfg := &Config{
+		ProjectSettings: &ProjectSettings{
+			Mask: &MaskSettings{Env: []string{"[DB_*"}, Patterns: []string{"ghp_(["}},
+		},
+		Panes: []ConfigPane{
+			{Name: "pane1", Dir: "/tmp", Start: "echo start", Stop: "echo stop"},
+		},
+	}
+	err := cfg.LoadConfigFromStruct()
+	if err == nil || !strings.Contains(err.Error(), "mask.env has invalid pattern") ||
+		!strings.Contains(err.Error(), "mask.patterns has invalid regexp") {
+		t.Fatalf("expected invalid mask errors, got %v", err)
+	}
+
+	cfg.ProjectSettings.Mask = &MaskSettings{Env: []string{"DB_*"}, Patterns: []string{"ghp_[A-Za-z0-9]+"}}
+	if err := cfg.LoadConfigFromStruct(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	envNames, patterns := cfg.GetMaskSettings()
+	if want := []string{"*TOKEN*", "*SECRET*", "*PASSWORD*", "DB_*"}; !reflect.DeepEqual(envNames, want) {
+		t.Errorf("GetMaskSettings() env names = %v, want %v", envNames, want)
+	}
+	if want := []string{"ghp_[A-Za-z0-9]+"}; !reflect.DeepEqual(patterns, want) {
+		t.Errorf("GetMaskSettings() patterns = %v, want %v", patterns, want)
+	}
+}
+
+func Test_ConfigValidation_Type(t *testing.T) {
+	tests := []struct {
+		name    string
+		pane    ConfigPane
+		wantErr string
+	}{
+		{name: "task without stop", pane: ConfigPane{Type: "task"}},
+		{name: "service", pane: ConfigPane{Type: "service", Stop: "echo stop"}},
+		{
+			name:    "service without stop",
+			pane:    ConfigPane{},
+			wantErr: "pane[0] is missing required field: stop",
+		},
+		{
+			name:    "unsupported type",
+			pane:    ConfigPane{Type: "daemon", Stop: "echo stop"},
+			wantErr: "pane[0] has unsupported type: daemon",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.pane.Name, tt.pane.Dir, tt.pane.Start = "pane1", "/tmp", "echo start"
+			cfg := &Config{Panes: []ConfigPane{tt.pane}}
+			err := cfg.LoadConfigFromStruct()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected %q error, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func Test_ConfigValidation_Schedule(t *testing.T) {
+	tests := []struct {
+		name     string
+		schedule ScheduledCommand
+		wantErr  string
+	}{
+		{name: "cron", schedule: ScheduledCommand{Command: "make lint", Cron: "*/15 * * * *"}},
+		{name: "every", schedule: ScheduledCommand{Command: "make lint", Every: 5 * time.Minute}},
+		{
+			name:     "missing command",
+			schedule: ScheduledCommand{Every: time.Minute},
+			wantErr:  "pane[0].schedule[0] is missing required field: command",
+		},
+		{
+			name:     "neither cron nor every",
+			schedule: ScheduledCommand{Command: "make lint"},
+			wantErr:  "pane[0].schedule[0] must set exactly one of cron or every",
+		},
+		{
+			name:     "both cron and every",
+			schedule: ScheduledCommand{Command: "make lint", Cron: "@hourly", Every: time.Minute},
+			wantErr:  "pane[0].schedule[0] must set exactly one of cron or every",
+		},
+		{
+			name:     "negative every",
+			schedule: ScheduledCommand{Command: "make lint", Every: -time.Minute},
+			wantErr:  "pane[0].schedule[0] every must be positive",
+		},
+		{
+			name:     "invalid cron",
+			schedule: ScheduledCommand{Command: "make lint", Cron: "61 * * * *"},
+			wantErr:  "pane[0].schedule[0] has invalid cron",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Panes: []ConfigPane{{
+				Name:     "pane1",
+				Dir:      "/tmp",
+				Start:    "echo start",
+				Stop:     "echo stop",
+				Schedule: []ScheduledCommand{tt.schedule},
+			}}}
+			err := cfg.LoadConfigFromStruct()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected %q error, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func Test_ConfigValidation_Commands(t *testing.T) {
+	tests := []struct {
+		name    string
+		command ConfigCommand
+		wantErr string
+	}{
+		{
+			name:    "steps with reserved commands",
+			command: ConfigCommand{Steps: []string{"<stop_pane>", "make db-reset", "<start_pane>"}},
+		},
+		{
+			name:    "both command and steps",
+			command: ConfigCommand{Command: "make db-reset", Steps: []string{"<stop_pane>"}},
+			wantErr: "pane[0].commands.lowerR must not set both command and steps",
+		},
+		{
+			name:    "empty step",
+			command: ConfigCommand{Steps: []string{"<stop_pane>", " "}},
+			wantErr: "pane[0].commands.lowerR.steps[1] is empty",
+		},
+		{
+			name:    "invalid placeholder",
+			command: ConfigCommand{Command: `git checkout {{input Branch}}`},
+			wantErr: "pane[0].commands.lowerR has invalid placeholder {{input Branch}}",
+		},
+		{
+			name:    "invalid placeholder in a step",
+			command: ConfigCommand{Steps: []string{"<stop_pane>", `deploy {{choice "env"}}`}},
+			wantErr: `pane[0].commands.lowerR has invalid placeholder {{choice "env"}}: choice needs at least one option`,
+		},
+		{
+			name:    "modal reserved command",
+			command: ConfigCommand{Steps: []string{"<git_panel>"}},
+			wantErr: "pane[0].commands.lowerR.steps[0] has unsupported reserved command: <git_panel>",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Panes: []ConfigPane{{
+				Name:     "pane1",
+				Dir:      "/tmp",
+				Start:    "echo start",
+				Stop:     "echo stop",
+				Commands: &ConfigCommands{LowerR: &tt.command},
+			}}}
+			err := cfg.LoadConfigFromStruct()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected %q error, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func Test_ConfigValidation_Stop(t *testing.T) {
+	cfg := &Config{
+		Panes: []ConfigPane{
+			{
+				Name:           "pane1",
+				Dir:            "/tmp",
+				Start:          "echo start",
+				Stop:           "echo stop",
+				StopSignal:     "SIGNOPE",
+				StopTimeout:    -time.Second,
+				StopEscalation: []StopStep{{Signal: "HUP", Timeout: -time.Second}, {Signal: "bogus"}},
+			},
+		},
+	}
+	err := cfg.LoadConfigFromStruct()
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	for _, want := range []string{
+		"pane[0] has unknown stop_signal: SIGNOPE",
+		"pane[0] stop_timeout must not be negative",
+		"pane[0].stop_escalation[0] timeout must not be negative",
+		"pane[0].stop_escalation[1] has unknown signal: bogus",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in error, got: %v", want, err)
 		}
 	}
-	if len(validationErrors) > 0 {
-		return fmt.Errorf(
-			"configuration validation errors:\n%s",
-			strings.Join(validationErrors, "\n"),
-		)
+
+	cfg.Panes[0].StopSignal = "term"
+	cfg.Panes[0].StopTimeout = 10 * time.Second
+	cfg.Panes[0].StopEscalation = []StopStep{{Signal: "SIGQUIT", Timeout: time.Second}}
+	if err := cfg.LoadConfigFromStruct(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
+
+func Test_ConfigValidation_Shutdown(t *testing.T) {
+	tests := []struct {
+		name    string
+		order   string
+		panes   []ConfigPane
+		wantErr string
+	}{
+		{
+			name:    "unsupported order",
+			order:   "random",
+			panes:   []ConfigPane{{Name: "api"}},
+			wantErr: "project_settings.shutdown_order is unsupported: random",
+		},
+		{
+			name:    "unknown dependency",
+			panes:   []ConfigPane{{Name: "api", DependsOn: []string{"db"}}},
+			wantErr: "pane[0] depends_on has unknown pane: db",
+		},
+		{
+			name:    "self dependency",
+			panes:   []ConfigPane{{Name: "api", DependsOn: []string{"api"}}},
+			wantErr: "pane[0] depends_on has unknown pane: api",
+		},
+		{
+			name: "dependency cycle",
+			panes: []ConfigPane{
+				{Name: "api", DependsOn: []string{"db"}},
+				{Name: "db", DependsOn: []string{"api"}},
+			},
+			wantErr: "panes depends_on contains a cycle",
+		},
+		{
+			name:    "duplicate pane name",
+			panes:   []ConfigPane{{Name: "api"}, {Name: "db"}, {Name: "api"}},
+			wantErr: "pane[2] has the same name as pane[0]: api",
+		},
+		{
+			name:    "negative stop command timeout",
+			panes:   []ConfigPane{{Name: "api", StopCommandTimeout: -time.Second}},
+			wantErr: "pane[0] stop_command_timeout must not be negative",
+		},
+		{
+			name:  "valid",
+			order: "reverse",
+			panes: []ConfigPane{{Name: "db"}, {Name: "api", DependsOn: []string{"db"}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := range tt.panes {
+				tt.panes[i].Dir, tt.panes[i].Start, tt.panes[i].Stop = "/tmp", "echo start", "echo stop"
+			}
+			cfg := &Config{ProjectSettings: &ProjectSettings{ShutdownOrder: tt.order}, Panes: tt.panes}
+			err := cfg.LoadConfigFromStruct()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected %q error, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestConfig_GetOutputDir(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	tests := []struct {
+		name       string
+		projectDir string
+		outputDir  string
+		want       string
+	}{
+		{name: "absolute", projectDir: "/work", outputDir: "/var/log/localdev", want: "/var/log/localdev"},
+		{name: "relative to project dir", projectDir: "/work", outputDir: "logs", want: "/work/logs"},
+		{name: "relative without project dir", outputDir: "logs", want: "logs"},
+		{name: "home dir", projectDir: "/work", outputDir: "~/logs", want: filepath.Join(home, "logs")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{ProjectSettings: &ProjectSettings{Dir: tt.projectDir, OutputDir: tt.outputDir}}
+			got, err := cfg.GetOutputDir()
+			if err != nil {
+				t.Fatalf("GetOutputDir() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("GetOutputDir() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfig_GetShutdownWaits(t *testing.T) {
+	panes := []ConfigPane{
+		{Name: "db"},
+		{Name: "api", DependsOn: []string{"db", "cache"}},
+		{Name: "cache"},
+		{Name: "web", DependsOn: []string{"api"}},
 	}
-	return nil
+	tests := []struct {
+		name  string
+		order string
+		panes []ConfigPane
+		want  [][]int
+	}{
+		{
+			name:  "concurrent without dependencies",
+			panes: []ConfigPane{{Name: "db"}, {Name: "api"}},
+			want:  [][]int{nil, nil},
+		},
+		{
+			name:  "concurrent waits for dependents",
+			panes: panes,
+			want:  [][]int{{1}, {3}, {1}, nil},
+		},
+		{
+			name:  "reverse declaration order",
+			order: "reverse",
+			panes: []ConfigPane{{Name: "db"}, {Name: "api"}, {Name: "web"}},
+			want:  [][]int{{1}, {2}, nil},
+		},
+		{
+			// Stops cache, web, api and db one at a time.
+			name:  "reverse order respects dependencies",
+			order: "reverse",
+			panes: []ConfigPane{
+				{Name: "db"},
+				{Name: "web", DependsOn: []string{"api"}},
+				{Name: "api", DependsOn: []string{"db"}},
+				{Name: "cache"},
+			},
+			want: [][]int{{2}, {3}, {1}, nil},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{ProjectSettings: &ProjectSettings{ShutdownOrder: tt.order}, Panes: tt.panes}
+			if got := cfg.GetShutdownWaits(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetShutdownWaits() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// Helper for testing validation logic directly
+func (c *Config) LoadConfigFromStruct() error {
+	return c.validate()
 }
@@ -1,11 +1,17 @@
 package config
 
+import "time"
+
 // ConfigCommand represents a single command configuration for a pane.
 type ConfigCommand struct {
-	Command     string `yaml:"command"`
-	Description string `yaml:"description"`
-	Silent      bool   `yaml:"silent"`
-	AutoExecute bool   `yaml:"autoExecute"`
+	Command string `yaml:"command"`
+	// Steps is run instead of Command: shell or reserved commands run in order until one fails.
+	Steps       []string `yaml:"steps,omitempty"`
+	Description string   `yaml:"description"`
+	Silent      bool     `yaml:"silent"`
+	AutoExecute bool     `yaml:"autoExecute"`
+	// Confirm is a question asked in a yes/no modal before the command runs.
+	Confirm string `yaml:"confirm,omitempty"`
 }
 
 // ConfigCommands holds all configurable commands for a pane.
@@ -65,10 +71,54 @@ type ConfigCommands struct {
 	UpperZ *ConfigCommand `yaml:"upperZ,omitempty"`
 }
 
+// GitSettings holds project-level git configuration.
+type GitSettings struct {
+	// FetchInterval enables a periodic background git fetch of every pane repository when positive.
+	FetchInterval time.Duration `yaml:"fetch_interval,omitempty"`
+}
+
+// MaskSettings lists the secrets hidden in pane output and logs.
+type MaskSettings struct {
+	// Env lists glob patterns of env var names whose values are masked, in addition to the defaults.
+	Env []string `yaml:"env,omitempty"`
+	// Patterns lists regular expressions whose matches are masked.
+	Patterns []string `yaml:"patterns,omitempty"`
+}
+
 // ProjectSettings holds project-level configuration.
 type ProjectSettings struct {
 	Dir     string `yaml:"dir,omitempty"`
 	Command string `yaml:"command,omitempty"`
+	// OutputDir is where <save_pane_output> writes pane output files.
+	OutputDir string       `yaml:"output_dir,omitempty"`
+	Git       *GitSettings `yaml:"git,omitempty"`
+	// Env sets variables for every pane, overriding EnvFile and the project command.
+	Env map[string]string `yaml:"env,omitempty"`
+	// EnvFile lists dotenv files loaded in order; relative paths resolve beneath Dir.
+	EnvFile []string `yaml:"env_file,omitempty"`
+	// Mask adds env var names and patterns to hide in pane output and logs.
+	Mask *MaskSettings `yaml:"mask,omitempty"`
+	// ShutdownOrder is "concurrent" (default) to stop panes at once on exit, or "reverse" to stop
+	// them one at a time in reverse declaration order.
+	ShutdownOrder string `yaml:"shutdown_order,omitempty"`
+}
+
+// StopStep is an escalation step of a pane stop sequence.
+type StopStep struct {
+	// Signal is a signal name such as "SIGTERM" or "TERM".
+	Signal string `yaml:"signal"`
+	// Timeout is how long to wait for the process group to exit before the next step.
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// ScheduledCommand is a command run silently in the background of a pane on a schedule.
+type ScheduledCommand struct {
+	Command     string `yaml:"command"`
+	Description string `yaml:"description,omitempty"`
+	// Cron is a five-field cron expression such as "*/15 9-18 * * 1-5" or a descriptor such as "@hourly".
+	Cron string `yaml:"cron,omitempty"`
+	// Every runs the command at a fixed interval instead of Cron.
+	Every time.Duration `yaml:"every,omitempty"`
 }
 
 // ConfigPane represents the configuration for a single pane.
@@ -78,10 +128,40 @@ type ConfigPane struct {
 	Start    string          `yaml:"start"`
 	Stop     string          `yaml:"stop"`
 	Commands *ConfigCommands `yaml:"commands,omitempty"`
+	// Type is "service" (default) for a long-running process or "task" for a job that runs to
+	// completion; Stop is optional for tasks.
+	Type string `yaml:"type,omitempty"`
+	// Format selects how output lines are rendered: "text" (default) or "json".
+	Format string `yaml:"format,omitempty"`
+	// FormatFields lists the extra JSON keys shown after the message; all keys when empty.
+	FormatFields []string `yaml:"format_fields,omitempty"`
+	// RestartOnBranchChange restarts the pane when the checked out branch of its dir changes.
+	RestartOnBranchChange bool `yaml:"restart_on_branch_change,omitempty"`
+	// Env sets variables for the pane's commands, overriding EnvFile and the project env.
+	Env map[string]string `yaml:"env,omitempty"`
+	// EnvFile lists dotenv files loaded in order; relative paths resolve beneath the pane dir.
+	EnvFile []string `yaml:"env_file,omitempty"`
+	// Setup runs in Dir before every start; the env vars it changes apply to the pane's commands.
+	Setup string `yaml:"setup,omitempty"`
+	// StopSignal is the first signal sent to the start process group when stopping; SIGINT when empty.
+	StopSignal string `yaml:"stop_signal,omitempty"`
+	// StopTimeout is how long to wait after StopSignal; 3s when zero.
+	StopTimeout time.Duration `yaml:"stop_timeout,omitempty"`
+	// StopEscalation lists further signals sent in order while the process group keeps running.
+	// SIGKILL is always sent last.
+	StopEscalation []StopStep `yaml:"stop_escalation,omitempty"`
+	// StopCommandTimeout limits how long Stop may run on exit; 30s when zero.
+	StopCommandTimeout time.Duration `yaml:"stop_command_timeout,omitempty"`
+	// DependsOn names the panes this pane needs; on exit it is stopped before them.
+	DependsOn []string `yaml:"depends_on,omitempty"`
+	// Schedule lists commands run in Dir with the pane env on a cron schedule or interval.
+	Schedule []ScheduledCommand `yaml:"schedule,omitempty"`
 }
 
 // Config represents the overall application configuration.
 type Config struct {
 	ProjectSettings *ProjectSettings `yaml:"project_settings,omitempty"`
 	Panes           []ConfigPane     `yaml:"panes"`
+	// path is the file the configuration was loaded from.
+	path string
 }
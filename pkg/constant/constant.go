@@ -1,25 +1,71 @@
 package constant
 
+import "time"
+
 var Page = struct {
-	MainPage               string
-	CommandOutputModalPage string
-	CommandHelpModalPage   string
-	MaximizedPane          string
+	MainPage                string
+	CommandOutputModalPage  string
+	CommandHelpModalPage    string
+	PaneHistoryModalPage    string
+	GitPanelModalPage       string
+	ConfirmModalPage        string
+	CommandFormModalPage    string
+	CommandPaletteModalPage string
+	MaximizedPane           string
 }{
-	MainPage:               "main",
-	CommandOutputModalPage: "command_output_modal",
-	CommandHelpModalPage:   "command_help_modal",
-	MaximizedPane:          "maximized_pane",
+	MainPage:                "main",
+	CommandOutputModalPage:  "command_output_modal",
+	CommandHelpModalPage:    "command_help_modal",
+	PaneHistoryModalPage:    "pane_history_modal",
+	GitPanelModalPage:       "git_panel_modal",
+	ConfirmModalPage:        "confirm_modal",
+	CommandFormModalPage:    "command_form_modal",
+	CommandPaletteModalPage: "command_palette_modal",
+	MaximizedPane:           "maximized_pane",
 }
 
 var ReservedCommand = struct {
-	TogglePaneSize string
-	StartPane      string
-	StopPane       string
+	TogglePaneSize  string
+	StartPane       string
+	StopPane        string
+	ShowPaneHistory string
+	ClearPane       string
+	SavePaneOutput  string
+	GitPanel        string
+}{
+	TogglePaneSize:  "<toggle_pane_size>",
+	StartPane:       "<start_pane>",
+	StopPane:        "<stop_pane>",
+	ShowPaneHistory: "<show_pane_history>",
+	ClearPane:       "<clear_pane>",
+	SavePaneOutput:  "<save_pane_output>",
+	GitPanel:        "<git_panel>",
+}
+
+var PaneOutputFormat = struct {
+	Text string
+	JSON string
+}{
+	Text: "text",
+	JSON: "json",
+}
+
+// PaneType lists the supported values of a pane's type.
+var PaneType = struct {
+	Service string
+	Task    string
+}{
+	Service: "service",
+	Task:    "task",
+}
+
+// ShutdownOrder lists the supported values of project_settings.shutdown_order.
+var ShutdownOrder = struct {
+	Concurrent string
+	Reverse    string
 }{
-	TogglePaneSize: "<toggle_pane_size>",
-	StartPane:      "<start_pane>",
-	StopPane:       "<stop_pane>",
+	Concurrent: "concurrent",
+	Reverse:    "reverse",
 }
 
 var AnsiColor = struct {
@@ -37,3 +83,25 @@ var AnsiColor = struct {
 // - Performance: Rendering too many lines can degrade UI responsiveness.
 // - Usability: Limiting the output prevents overwhelming the user with excessive information.
 var MaxPaneOutputLines = 500
+
+// MaxPaneHistoryLines defines the maximum number of raw output lines kept per pane.
+// The history outlives the pane's visible buffer so the full-history view and saved
+// output can show raw lines even when the pane renders them in a different format.
+var MaxPaneHistoryLines = 5000
+
+// GitStatusRefreshInterval defines how often the git status shown in pane titles is refreshed
+// when no change to the repository's HEAD or refs has been detected.
+var GitStatusRefreshInterval = 10 * time.Second
+
+// DefaultMaskedEnvNames are the glob patterns of env var names whose values are always masked.
+var DefaultMaskedEnvNames = []string{"*TOKEN*", "*SECRET*", "*PASSWORD*"}
+
+// DefaultStopSignal is the signal first sent to a pane's start process group when it stops.
+const DefaultStopSignal = "SIGINT"
+
+// DefaultStopTimeout is how long a pane's start process group gets to exit after DefaultStopSignal.
+const DefaultStopTimeout = 3 * time.Second
+
+// DefaultStopCommandTimeout is how long a pane's stop command may run on exit before its process
+// group is killed.
+const DefaultStopCommandTimeout = 30 * time.Second
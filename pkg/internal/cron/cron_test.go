@@ -0,0 +1,60 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse_Errors(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+		"@every",
+	} {
+		if _, err := Parse(expr); err == nil {
+			t.Errorf("Parse(%q) error = nil, want an error", expr)
+		}
+	}
+}
+
+func TestSchedule_Next(t *testing.T) {
+	// Wednesday.
+	from := time.Date(2026, time.March, 4, 10, 17, 30, 0, time.UTC)
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{expr: "* * * * *", want: time.Date(2026, time.March, 4, 10, 18, 0, 0, time.UTC)},
+		{expr: "*/15 * * * *", want: time.Date(2026, time.March, 4, 10, 30, 0, 0, time.UTC)},
+		{expr: "5/20 * * * *", want: time.Date(2026, time.March, 4, 10, 25, 0, 0, time.UTC)},
+		{expr: "0 9-17 * * *", want: time.Date(2026, time.March, 4, 11, 0, 0, 0, time.UTC)},
+		{expr: "30 8,20 * * *", want: time.Date(2026, time.March, 4, 20, 30, 0, 0, time.UTC)},
+		{expr: "0 9 * * 1-5", want: time.Date(2026, time.March, 5, 9, 0, 0, 0, time.UTC)},
+		{expr: "0 0 * * 7", want: time.Date(2026, time.March, 8, 0, 0, 0, 0, time.UTC)},
+		{expr: "@daily", want: time.Date(2026, time.March, 5, 0, 0, 0, 0, time.UTC)},
+		{expr: "@monthly", want: time.Date(2026, time.April, 1, 0, 0, 0, 0, time.UTC)},
+		{expr: "0 0 29 2 *", want: time.Date(2028, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		// Both day fields restricted: either matches, so the next Friday comes before the 15th.
+		{expr: "0 0 15 * 5", want: time.Date(2026, time.March, 6, 0, 0, 0, 0, time.UTC)},
+		{expr: "0 0 31 2 *", want: time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			s, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if got := s.Next(from); !got.Equal(tt.want) {
+				t.Errorf("Next() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
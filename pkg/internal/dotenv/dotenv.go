@@ -0,0 +1,129 @@
+package dotenv
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+var keyRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.]*$`)
+
+// ReadFile parses the dotenv file at path.
+func ReadFile(path string) (map[string]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	vars, err := Parse(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return vars, nil
+}
+
+// Parse parses dotenv content into a map of variable names to values.
+//
+// Each line holds KEY=VALUE, optionally prefixed with "export". Blank lines and lines starting
+// with # are ignored. Unquoted values end at a " #" comment and are trimmed. Single quoted values
+// are taken literally; double quoted values may span lines and support the \n, \r, \t, \" and
+// \\ escapes. A later assignment of the same key wins.
+func Parse(content string) (map[string]string, error) {
+	vars := make(map[string]string)
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		lineNumber := i + 1
+		line := strings.TrimSpace(lines[i])
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if rest, ok := strings.CutPrefix(line, "export"); ok && (rest == "" || rest[0] == ' ' || rest[0] == '\t') {
+			line = strings.TrimSpace(rest)
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || !keyRegex.MatchString(key) {
+			return nil, fmt.Errorf("line %d: expected KEY=VALUE", lineNumber)
+		}
+		value = strings.TrimLeft(value, " \t")
+
+		switch {
+		case strings.HasPrefix(value, "'"):
+			end := strings.Index(value[1:], "'")
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated single quote", lineNumber)
+			}
+			vars[key] = value[1 : end+1]
+			if err := checkTrailing(value[end+2:]); err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+			}
+		case strings.HasPrefix(value, `"`):
+			// A double quoted value continues on the following lines until the closing quote.
+			raw := value[1:]
+			for {
+				unquoted, trailing, closed := unquoteDouble(raw)
+				if closed {
+					vars[key] = unquoted
+					if err := checkTrailing(trailing); err != nil {
+						return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+					}
+					break
+				}
+				i++
+				if i >= len(lines) {
+					return nil, fmt.Errorf("line %d: unterminated double quote", lineNumber)
+				}
+				raw += "\n" + lines[i]
+			}
+		default:
+			if j := strings.Index(value, " #"); j >= 0 {
+				value = value[:j]
+			} else if j := strings.Index(value, "\t#"); j >= 0 {
+				value = value[:j]
+			}
+			vars[key] = strings.TrimSpace(value)
+		}
+	}
+	return vars, nil
+}
+
+// unquoteDouble decodes s up to the first unescaped double quote. It returns the decoded value,
+// the text after the quote and whether the quote was found.
+func unquoteDouble(s string) (value, trailing string, closed bool) {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '"':
+			return b.String(), s[i+1:], true
+		case c == '\\' && i+1 < len(s):
+			i++
+			switch s[i] {
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			case '"', '\\':
+				b.WriteByte(s[i])
+			default:
+				b.WriteByte('\\')
+				b.WriteByte(s[i])
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", "", false
+}
+
+// checkTrailing rejects anything but whitespace or a comment after a closing quote.
+func checkTrailing(s string) error {
+	s = strings.TrimSpace(s)
+	if s != "" && !strings.HasPrefix(s, "#") {
+		return fmt.Errorf("unexpected text after closing quote: %q", s)
+	}
+	return nil
+}
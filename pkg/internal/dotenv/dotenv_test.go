@@ -0,0 +1,84 @@
+package dotenv_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/jiyeol-lee/localdev/pkg/internal/dotenv"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name:    "plain values, comments and blank lines",
+			content: "# comment\n\nPORT=3001\nHOST = localhost \nEMPTY=\n",
+			want:    map[string]string{"PORT": "3001", "HOST": "localhost", "EMPTY": ""},
+		},
+		{
+			name:    "export prefix",
+			content: "export API_URL=http://localhost\nexported=1\n",
+			want:    map[string]string{"API_URL": "http://localhost", "exported": "1"},
+		},
+		{
+			name:    "inline comment on unquoted value",
+			content: "COLOR=blue # favorite\nHASH=a#b\n",
+			want:    map[string]string{"COLOR": "blue", "HASH": "a#b"},
+		},
+		{
+			name:    "single quotes are literal",
+			content: `SECRET='p@ss #not comment \n' # comment`,
+			want:    map[string]string{"SECRET": `p@ss #not comment \n`},
+		},
+		{
+			name:    "double quotes with escapes",
+			content: `GREETING="hello \"world\"\nbye\\"`,
+			want:    map[string]string{"GREETING": "hello \"world\"\nbye\\"},
+		},
+		{
+			name:    "multi-line double quoted value",
+			content: "CERT=\"-----BEGIN-----\nabc\n-----END-----\"\nNEXT=1\r\n",
+			want:    map[string]string{"CERT": "-----BEGIN-----\nabc\n-----END-----", "NEXT": "1"},
+		},
+		{
+			name:    "later assignment wins",
+			content: "A=1\nA=2\n",
+			want:    map[string]string{"A": "2"},
+		},
+		{name: "missing equals sign", content: "JUST_A_KEY\n", wantErr: true},
+		{name: "invalid key", content: "1ABC=x\n", wantErr: true},
+		{name: "unterminated double quote", content: "A=\"open\nB=1\n", wantErr: true},
+		{name: "unterminated single quote", content: "A='open\n", wantErr: true},
+		{name: "text after closing quote", content: `A="x" y`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := dotenv.Parse(tt.content)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte("PORT=3001\nBAD LINE\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dotenv.ReadFile(path); err == nil {
+		t.Fatal("ReadFile() error = nil, want a parse error")
+	}
+	if _, err := dotenv.ReadFile(filepath.Join(t.TempDir(), "missing.env")); !os.IsNotExist(err) {
+		t.Fatalf("ReadFile(missing) error = %v, want not exist", err)
+	}
+}
@@ -2,18 +2,77 @@ package env_vars
 
 import (
 	"bytes"
+	"context"
 	"fmt"
+	"io"
+	"maps"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
 
+	"github.com/jiyeol-lee/localdev/pkg/internal/dotenv"
 	"github.com/jiyeol-lee/localdev/pkg/internal/shell"
+	"golang.org/x/sys/unix"
 )
 
-// RunCommandAndCaptureEnvVars runs the given command in a shell and captures the environment variables before and after its execution.
-// It returns the paths to temporary files containing the environment variable dumps before and after the command execution.
-func RunCommandAndCaptureEnvVars(
-	command string,
+// DumpEnvArg is the hidden first argument that makes the localdev binary write its environment
+// to a file and exit. The shell runs it to report its variables in a format that, unlike printenv
+// output, keeps multi-line values intact.
+const DumpEnvArg = "__localdev_dump_env"
+
+// RunDumpEnvHelper handles an invocation of the binary with DumpEnvArg. It reports whether args,
+// the program arguments without the program name, requested the helper.
+func RunDumpEnvHelper(args []string) (bool, error) {
+	if len(args) == 0 || args[0] != DumpEnvArg {
+		return false, nil
+	}
+	if len(args) != 2 {
+		return true, fmt.Errorf("usage: %s <file>", DumpEnvArg)
+	}
+	return true, DumpEnv(args[1])
+}
+
+// DumpEnv writes the current environment to path as NUL-terminated KEY=VALUE entries.
+func DumpEnv(path string) error {
+	var b bytes.Buffer
+	for _, kv := range os.Environ() {
+		b.WriteString(kv)
+		b.WriteByte(0)
+	}
+	return os.WriteFile(path, b.Bytes(), 0o600)
+}
+
+// CaptureEnvVarsChanges runs command in a shell in dir with the environment env (the current
+// process environment when nil) and returns how the command changed the environment.
+// The command's stdout and stderr are written to output, and the command is killed when ctx is done.
+func CaptureEnvVarsChanges(
+	ctx context.Context,
+	command, dir string,
+	env []string,
+	output io.Writer,
+) (EnvVarsChanges, error) {
+	beforeFile, afterFile, err := runCommandAndCaptureEnvVars(ctx, command, dir, env, output)
+	defer os.Remove(beforeFile)
+	defer os.Remove(afterFile)
+	if err != nil {
+		return EnvVarsChanges{}, err
+	}
+	return GetEnvVarsChanges(beforeFile, afterFile)
+}
+
+func runCommandAndCaptureEnvVars(
+	ctx context.Context,
+	command, dir string,
+	env []string,
+	output io.Writer,
 ) (beforeCommandEnvVars, afterCommandEnvVars string, err error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", "", fmt.Errorf("error locating executable for env dump: %w", err)
+	}
 	fb, err := os.CreateTemp("", "envdump-before-")
 	if err != nil {
 		return "", "", err
@@ -24,25 +83,46 @@ func RunCommandAndCaptureEnvVars(
 		_ = os.Remove(fb.Name())
 		return "", "", err
 	}
-	defer fb.Close()
-	defer fa.Close()
+	_ = fb.Close()
+	_ = fa.Close()
 
 	sh := shell.Current()
-	// Use portable printenv output (newline separated). BSD printenv (macOS) lacks -0.
-	cmdBefore := exec.Command(sh, "-c", "printenv")
-	cmdBefore.Stdout = fb
-	if err := cmdBefore.Run(); err != nil {
+	dump := func(path string) string {
+		return fmt.Sprintf("%s %s %s", shellQuote(exe), DumpEnvArg, shellQuote(path))
+	}
+	cmdBefore := exec.CommandContext(ctx, sh, "-c", dump(fb.Name()))
+	cmdBefore.Dir = dir
+	cmdBefore.Env = env
+	if out, err := cmdBefore.CombinedOutput(); err != nil {
+		_ = os.Remove(fb.Name())
+		_ = os.Remove(fa.Name())
+		if message := strings.TrimSpace(string(out)); message != "" {
+			return "", "", fmt.Errorf("%w: %s", err, message)
+		}
 		return "", "", err
 	}
 
-	cmdAfter := exec.Command(
-		sh,
-		"-c",
-		fmt.Sprintf(`{ %s; }; printenv > %q`, command, fa.Name()),
+	// The newline lets the command end with a comment. The exit status of the command is kept, as
+	// the dump would otherwise replace it.
+	script := fmt.Sprintf(
+		"{ %s\n}; __ld_status=$?; [ \"$__ld_status\" -eq 0 ] || exit \"$__ld_status\"; %s",
+		command,
+		dump(fa.Name()),
 	)
-	cmdAfter.Stdout = os.Stdout
-	cmdAfter.Stderr = os.Stderr
+	cmdAfter := exec.CommandContext(ctx, sh, "-c", script)
+	cmdAfter.Dir = dir
+	cmdAfter.Env = env
+	cmdAfter.Stdout = output
+	cmdAfter.Stderr = output
+	// Kill the whole process group on cancellation so background children cannot keep the
+	// output pipe open.
+	cmdAfter.SysProcAttr = &unix.SysProcAttr{Setpgid: true}
+	cmdAfter.Cancel = func() error {
+		return unix.Kill(-cmdAfter.Process.Pid, unix.SIGKILL)
+	}
+	cmdAfter.WaitDelay = time.Second
 	if err := cmdAfter.Run(); err != nil {
+		_ = os.Remove(fa.Name())
 		// Return the before file so caller can still inspect the original env on failure.
 		return fb.Name(), "", err
 	}
@@ -50,43 +130,127 @@ func RunCommandAndCaptureEnvVars(
 	return fb.Name(), fa.Name(), nil
 }
 
-// GetDiffEnvVars compares the environment variable dumps in the given files and returns a slice of strings
-func GetDiffEnvVars(
-	beforeFile, afterFile string,
-) ([]string, error) {
+// shellQuote quotes s as a single word for a POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// EnvVarsChanges describes how a command changed the environment variables.
+type EnvVarsChanges struct {
+	// Added holds KEY=VALUE entries for variables the command defined.
+	Added []string
+	// Changed holds KEY=VALUE entries for variables the command assigned a new value.
+	Changed []string
+	// Removed holds the names of variables the command unset.
+	Removed []string
+}
+
+// IsEmpty reports whether the command left the environment unchanged.
+func (c EnvVarsChanges) IsEmpty() bool {
+	return len(c.Added) == 0 && len(c.Changed) == 0 && len(c.Removed) == 0
+}
+
+// Apply returns a copy of base with the removed variables dropped and the added and changed
+// variables set, so a process started with it sees the environment the command left behind.
+func (c EnvVarsChanges) Apply(base []string) []string {
+	overridden := make(map[string]bool, len(c.Added)+len(c.Changed)+len(c.Removed))
+	for _, name := range c.Removed {
+		overridden[name] = true
+	}
+	for _, kv := range slices.Concat(c.Added, c.Changed) {
+		name, _, _ := strings.Cut(kv, "=")
+		overridden[name] = true
+	}
+
+	env := make([]string, 0, len(base)+len(c.Added))
+	for _, kv := range base {
+		name, _, _ := strings.Cut(kv, "=")
+		if !overridden[name] {
+			env = append(env, kv)
+		}
+	}
+	return append(append(env, c.Added...), c.Changed...)
+}
+
+// Set returns a copy of base with vars, given as KEY=VALUE entries, added or replacing the
+// variables of the same name.
+func Set(base []string, vars []string) []string {
+	return EnvVarsChanges{Changed: vars}.Apply(base)
+}
+
+// Load reads the dotenv files in order and then applies vars, so later files override earlier
+// ones and vars override every file. Relative file paths resolve beneath dir. It returns KEY=VALUE
+// entries sorted by name.
+func Load(vars map[string]string, files []string, dir string) ([]string, error) {
+	merged := make(map[string]string)
+	for _, file := range files {
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(dir, file)
+		}
+		fileVars, err := dotenv.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("error reading env file: %w", err)
+		}
+		maps.Copy(merged, fileVars)
+	}
+	maps.Copy(merged, vars)
+
+	env := make([]string, 0, len(merged))
+	for _, k := range slices.Sorted(maps.Keys(merged)) {
+		env = append(env, fmt.Sprintf("%s=%s", k, merged[k]))
+	}
+	return env, nil
+}
+
+// GetEnvVarsChanges compares the environment variable dumps in the given files and returns the
+// variables added, changed and removed between them, each sorted by name.
+func GetEnvVarsChanges(beforeFile, afterFile string) (EnvVarsChanges, error) {
 	beforeData, err := os.ReadFile(beforeFile)
 	if err != nil {
-		return nil, err
+		return EnvVarsChanges{}, err
 	}
 	afterData, err := os.ReadFile(afterFile)
 	if err != nil {
-		return nil, err
-	}
-
-	parseEnv := func(b []byte) map[string]string {
-		m := make(map[string]string)
-		// Normalize any NUL separators to newlines for portability.
-		b = bytes.ReplaceAll(b, []byte{0}, []byte{'\n'})
-		for line := range bytes.SplitSeq(b, []byte{'\n'}) {
-			if len(line) == 0 {
-				continue
-			}
-			if i := bytes.IndexByte(line, '='); i > 0 {
-				m[string(line[:i])] = string(line[i+1:])
-			}
-		}
-		return m
+		return EnvVarsChanges{}, err
 	}
 
 	before := parseEnv(beforeData)
 	after := parseEnv(afterData)
 
-	newVars := []string{}
-	for k, v := range after {
-		if old, ok := before[k]; !ok || old != v {
-			newVars = append(newVars, fmt.Sprintf("%s=%s", k, v))
+	var changes EnvVarsChanges
+	for _, k := range slices.Sorted(maps.Keys(after)) {
+		old, ok := before[k]
+		switch {
+		case !ok:
+			changes.Added = append(changes.Added, fmt.Sprintf("%s=%s", k, after[k]))
+		case old != after[k]:
+			changes.Changed = append(changes.Changed, fmt.Sprintf("%s=%s", k, after[k]))
+		}
+	}
+	for _, k := range slices.Sorted(maps.Keys(before)) {
+		if _, ok := after[k]; !ok {
+			changes.Removed = append(changes.Removed, k)
 		}
 	}
+	return changes, nil
+}
 
-	return newVars, nil
+// parseEnv parses an environment dump into a map of variable names to values.
+func parseEnv(b []byte) map[string]string {
+	m := make(map[string]string)
+	// Dumps written by DumpEnv are NUL-terminated, so values may contain newlines.
+	// Fall back to newline separated printenv output otherwise.
+	sep := []byte{'\n'}
+	if bytes.IndexByte(b, 0) >= 0 {
+		sep = []byte{0}
+	}
+	for line := range bytes.SplitSeq(b, sep) {
+		if len(line) == 0 {
+			continue
+		}
+		if i := bytes.IndexByte(line, '='); i > 0 {
+			m[string(line[:i])] = string(line[i+1:])
+		}
+	}
+	return m
 }
@@ -1,209 +1,266 @@
 package env_vars_test
 
 import (
+	"bytes"
+	"context"
+	"io"
 	"os"
 	"path/filepath"
 	"reflect"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	envvars "github.com/jiyeol-lee/localdev/pkg/internal/env_vars"
+	"github.com/jiyeol-lee/localdev/pkg/internal/env_vars/envvarstest"
 )
 
-func TestRunCommandAndCaptureEnvVars(t *testing.T) {
-	type args struct {
-		command string
-	}
+// TestMain lets the shell run this test binary as the env dump helper, as it does the
+// localdev binary.
+func TestMain(m *testing.M) {
+	envvarstest.Main(m)
+}
+
+func TestCaptureEnvVarsChanges(t *testing.T) {
 	tests := []struct {
-		name   string
-		args   args
-		assert func(t *testing.T, beforeFile, afterFile string, err error)
+		name    string
+		command string
+		want    func(changes envvars.EnvVarsChanges) bool
+		wantErr bool
 	}{
 		{
-			name: "command adds single variable",
-			args: args{command: "TEST_ADDED_VAR=hello; export TEST_ADDED_VAR"},
-			assert: func(t *testing.T, beforeFile, afterFile string, err error) {
-				if err != nil {
-					t.Fatalf("unexpected error: %v", err)
-				}
-				for _, f := range []string{beforeFile, afterFile} {
-					info, statErr := os.Stat(f)
-					if statErr != nil {
-						t.Fatalf("expected file %s to exist: %v", f, statErr)
-					}
-					if info.Size() == 0 {
-						t.Fatalf("expected file %s not to be empty", f)
-					}
-				}
-				diff, diffErr := envvars.GetDiffEnvVars(beforeFile, afterFile)
-				if diffErr != nil {
-					t.Fatalf("diff error: %v", diffErr)
-				}
-				found := false
-				for _, line := range diff {
-					if strings.HasPrefix(line, "TEST_ADDED_VAR=") {
-						found = true
-						break
-					}
-				}
-				if !found {
-					t.Fatalf("expected TEST_ADDED_VAR in diff, got %v", diff)
-				}
+			name:    "command adds single variable",
+			command: "TEST_ADDED_VAR=hello; export TEST_ADDED_VAR",
+			want: func(changes envvars.EnvVarsChanges) bool {
+				return slices.Contains(changes.Added, "TEST_ADDED_VAR=hello")
 			},
 		},
 		{
-			name: "command modifies existing variable",
-			args: args{command: "PATH=/tmp/custom:$PATH; export PATH"},
-			assert: func(t *testing.T, beforeFile, afterFile string, err error) {
-				if err != nil {
-					t.Fatalf("unexpected error: %v", err)
-				}
-				diff, diffErr := envvars.GetDiffEnvVars(beforeFile, afterFile)
-				if diffErr != nil {
-					t.Fatalf("diff error: %v", diffErr)
-				}
-				foundPath := false
-				for _, d := range diff {
-					if strings.HasPrefix(d, "PATH=") && strings.Contains(d, "/tmp/custom") {
-						foundPath = true
-					}
-				}
-				if !foundPath {
-					t.Fatalf("expected modified PATH in diff, got %v", diff)
-				}
+			name:    "command modifies existing variable",
+			command: "PATH=/tmp/custom:$PATH; export PATH",
+			want: func(changes envvars.EnvVarsChanges) bool {
+				return slices.ContainsFunc(changes.Changed, func(kv string) bool {
+					return strings.HasPrefix(kv, "PATH=/tmp/custom:")
+				})
 			},
 		},
 		{
-			name: "failing command returns error and no after capture",
-			args: args{command: "exit 5"},
-			assert: func(t *testing.T, beforeFile, afterFile string, err error) {
-				if err == nil {
-					t.Fatalf("expected error for failing command")
-				}
-				if beforeFile == "" {
-					t.Fatalf("expected before file path on failure (got empty)")
-				}
-				if afterFile != "" {
-					t.Fatalf("expected empty after file path on failure (got %s)", afterFile)
-				}
-				if _, statErr := os.Stat(beforeFile); statErr != nil {
-					t.Fatalf("expected before file to exist: %v", statErr)
-				}
-			},
+			name:    "failing command returns error",
+			command: "exit 5",
+			wantErr: true,
+		},
+		{
+			name:    "failing last command returns error",
+			command: "TEST_PARTIAL_VAR=1; export TEST_PARTIAL_VAR; false",
+			wantErr: true,
+		},
+		{
+			name:    "failing ls returns error",
+			command: "ls /nonexistent",
+			wantErr: true,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			beforeFile, afterFile, err := envvars.RunCommandAndCaptureEnvVars(tt.args.command)
-			t.Cleanup(func() {
-				for _, f := range []string{beforeFile, afterFile} {
-					if f != "" {
-						_ = os.Remove(f)
-					}
-				}
-			})
-			for _, f := range []string{beforeFile, afterFile} {
-				if f == "" {
-					continue
-				}
-				if !strings.HasPrefix(f, os.TempDir()) && !filepath.IsAbs(f) {
-					t.Fatalf("expected temp file path, got %s", f)
-				}
+			got, err := envvars.CaptureEnvVarsChanges(context.Background(), tt.command, "", nil, io.Discard)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CaptureEnvVarsChanges() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.want != nil && !tt.want(got) {
+				t.Errorf("CaptureEnvVarsChanges() = %+v", got)
 			}
-			tt.assert(t, beforeFile, afterFile, err)
 		})
 	}
 }
 
-func TestGetDiffEnvVars(t *testing.T) {
-	type args struct{ beforeFile, afterFile string }
+func TestCaptureEnvVarsChanges_RunsInDirWithEnv(t *testing.T) {
+	dir := t.TempDir()
+	got, err := envvars.CaptureEnvVarsChanges(
+		context.Background(),
+		`TEST_DIR="$PWD"; export TEST_DIR`,
+		dir,
+		[]string{"PATH=" + os.Getenv("PATH"), "TEST_BASE=1"},
+		io.Discard,
+	)
+	if err != nil {
+		t.Fatalf("CaptureEnvVarsChanges() error = %v", err)
+	}
+	want := envvars.EnvVarsChanges{Added: []string{"TEST_DIR=" + dir}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CaptureEnvVarsChanges() = %+v, want %+v", got, want)
+	}
+}
+
+func TestGetEnvVarsChanges(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	beforeFile := write("before", "KEEP=1\nCHANGE=old\nREMOVE=gone\n")
+	afterFile := write("after", "KEEP=1\nCHANGE=new\nADD=x=y\n")
+
+	got, err := envvars.GetEnvVarsChanges(beforeFile, afterFile)
+	if err != nil {
+		t.Fatalf("GetEnvVarsChanges() error = %v", err)
+	}
+	want := envvars.EnvVarsChanges{
+		Added:   []string{"ADD=x=y"},
+		Changed: []string{"CHANGE=new"},
+		Removed: []string{"REMOVE"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GetEnvVarsChanges() = %+v, want %+v", got, want)
+	}
+}
+
+func TestEnvVarsChanges_Apply(t *testing.T) {
 	tests := []struct {
 		name    string
-		args    args
-		setup   func(t *testing.T) (beforePath, afterPath string)
+		changes envvars.EnvVarsChanges
+		base    []string
 		want    []string
-		wantErr bool
 	}{
 		{
-			name: "added and modified vars are returned",
-			setup: func(t *testing.T) (string, string) {
-				before, err := os.CreateTemp("", "env-before-")
-				if err != nil {
-					t.Fatalf("temp: %v", err)
-				}
-				after, err := os.CreateTemp("", "env-after-")
-				if err != nil {
-					t.Fatalf("temp: %v", err)
-				}
-				_, _ = before.WriteString("A=1\x00B=2\x00C=unchanged\x00")
-				_, _ = after.WriteString("A=1\x00B=3\x00C=unchanged\x00D=new\x00")
-				before.Close()
-				after.Close()
-				return before.Name(), after.Name()
-			},
-			want:    []string{"B=3", "D=new"},
-			wantErr: false,
+			name:    "no changes keeps base",
+			changes: envvars.EnvVarsChanges{},
+			base:    []string{"A=1", "B=2"},
+			want:    []string{"A=1", "B=2"},
 		},
 		{
-			name: "missing before file returns error",
-			setup: func(t *testing.T) (string, string) {
-				bf := filepath.Join(os.TempDir(), "nonexistent-before")
-				af, _ := os.CreateTemp("", "env-after-")
-				af.WriteString("X=1\x00")
-				af.Close()
-				return bf, af.Name()
+			name: "adds, overrides and removes",
+			changes: envvars.EnvVarsChanges{
+				Added:   []string{"C=3"},
+				Changed: []string{"A=10"},
+				Removed: []string{"B"},
 			},
-			want:    nil,
-			wantErr: true,
+			base: []string{"A=1", "B=2", "D=4"},
+			want: []string{"D=4", "C=3", "A=10"},
 		},
 		{
-			name: "no differences returns empty slice",
-			setup: func(t *testing.T) (string, string) {
-				before, _ := os.CreateTemp("", "env-before-")
-				after, _ := os.CreateTemp("", "env-after-")
-				before.WriteString("Z=9\x00Y=8\x00")
-				after.WriteString("Z=9\x00Y=8\x00")
-				before.Close()
-				after.Close()
-				return before.Name(), after.Name()
-			},
-			want:    []string{},
-			wantErr: false,
+			name:    "removing a missing variable is a no-op",
+			changes: envvars.EnvVarsChanges{Removed: []string{"MISSING"}},
+			base:    []string{"A=1"},
+			want:    []string{"A=1"},
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			beforePath, afterPath := tt.args.beforeFile, tt.args.afterFile
-			if tt.setup != nil {
-				beforePath, afterPath = tt.setup(t)
-				t.Cleanup(func() { _ = os.Remove(beforePath); _ = os.Remove(afterPath) })
-			}
-			got, err := envvars.GetDiffEnvVars(beforePath, afterPath)
-			if (err != nil) != tt.wantErr {
-				t.Errorf("GetDiffEnvVars() error = %v, wantErr %v", err, tt.wantErr)
-				return
-			}
-			if err != nil {
-				return
+			base := append([]string{}, tt.base...)
+			if got := tt.changes.Apply(base); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Apply() = %v, want %v", got, tt.want)
 			}
-			if tt.want != nil {
-				gotSorted := append([]string{}, got...)
-				wantSorted := append([]string{}, tt.want...)
-				sortFn := func(s []string) {
-					for i := range s {
-						for j := i + 1; j < len(s); j++ {
-							if s[j] < s[i] {
-								s[i], s[j] = s[j], s[i]
-							}
-						}
-					}
-				}
-				sortFn(gotSorted)
-				sortFn(wantSorted)
-				if !reflect.DeepEqual(gotSorted, wantSorted) {
-					t.Errorf("GetDiffEnvVars() = %v, want %v", gotSorted, wantSorted)
-				}
+			if !reflect.DeepEqual(base, tt.base) {
+				t.Errorf("Apply() modified base: %v", base)
 			}
 		})
 	}
 }
+
+func TestCaptureEnvVarsChanges_Unset(t *testing.T) {
+	t.Setenv("TEST_UNSET_VAR", "present")
+	changes, err := envvars.CaptureEnvVarsChanges(context.Background(), "unset TEST_UNSET_VAR", "", nil, io.Discard)
+	if err != nil {
+		t.Fatalf("CaptureEnvVarsChanges() error = %v", err)
+	}
+	if !slices.Contains(changes.Removed, "TEST_UNSET_VAR") {
+		t.Fatalf("Removed = %v, want it to contain TEST_UNSET_VAR", changes.Removed)
+	}
+	for _, kv := range changes.Apply(os.Environ()) {
+		if strings.HasPrefix(kv, "TEST_UNSET_VAR=") {
+			t.Fatalf("Apply() kept %q", kv)
+		}
+	}
+}
+
+func TestCaptureEnvVarsChanges_MultiLineValueAndOutput(t *testing.T) {
+	var output bytes.Buffer
+	changes, err := envvars.CaptureEnvVarsChanges(
+		context.Background(),
+		"echo loading; echo oops >&2; TEST_CERT=$(printf 'line1\\nOTHER=bogus\\nline3'); export TEST_CERT",
+		"",
+		nil,
+		&output,
+	)
+	if err != nil {
+		t.Fatalf("CaptureEnvVarsChanges() error = %v", err)
+	}
+	if got := output.String(); !strings.Contains(got, "loading\n") || !strings.Contains(got, "oops\n") {
+		t.Errorf("output = %q, want the command's stdout and stderr", got)
+	}
+	want := []string{"TEST_CERT=line1\nOTHER=bogus\nline3"}
+	if !reflect.DeepEqual(changes.Added, want) {
+		t.Errorf("Added = %q, want %q", changes.Added, want)
+	}
+}
+
+func TestCaptureEnvVarsChanges_Canceled(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := envvars.CaptureEnvVarsChanges(ctx, "sleep 30 & wait", "", nil, io.Discard); err == nil {
+		t.Fatal("expected an error for a canceled command")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("canceled command took %v to return", elapsed)
+	}
+}
+
+func TestRunDumpEnvHelper(t *testing.T) {
+	if handled, err := envvars.RunDumpEnvHelper([]string{"--config", "x.yml"}); handled || err != nil {
+		t.Fatalf("RunDumpEnvHelper(other args) = %v, %v, want false, nil", handled, err)
+	}
+	if handled, err := envvars.RunDumpEnvHelper([]string{envvars.DumpEnvArg}); !handled || err == nil {
+		t.Fatalf("RunDumpEnvHelper(missing file) = %v, %v, want true and an error", handled, err)
+	}
+
+	t.Setenv("TEST_DUMP_VAR", "a\nb")
+	path := filepath.Join(t.TempDir(), "env")
+	if handled, err := envvars.RunDumpEnvHelper([]string{envvars.DumpEnvArg, path}); !handled || err != nil {
+		t.Fatalf("RunDumpEnvHelper() = %v, %v, want true, nil", handled, err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(content, []byte("\x00TEST_DUMP_VAR=a\nb\x00")) &&
+		!bytes.HasPrefix(content, []byte("TEST_DUMP_VAR=a\nb\x00")) {
+		t.Errorf("dump = %q, want a NUL-terminated TEST_DUMP_VAR entry", content)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, ".env"), []byte("PORT=3000\nHOST=localhost\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	local := filepath.Join(t.TempDir(), "local.env")
+	if err := os.WriteFile(local, []byte("export PORT=3001\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := envvars.Load(map[string]string{"HOST": "0.0.0.0", "DEBUG": "1"}, []string{".env", local}, dir)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	want := []string{"DEBUG=1", "HOST=0.0.0.0", "PORT=3001"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Load() = %v, want %v", got, want)
+	}
+
+	if _, err := envvars.Load(nil, []string{"missing.env"}, dir); err == nil {
+		t.Error("Load() with a missing file error = nil, want an error")
+	}
+}
+
+func TestSet(t *testing.T) {
+	got := envvars.Set([]string{"A=1", "B=2"}, []string{"B=3", "C=4"})
+	want := []string{"A=1", "B=3", "C=4"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Set() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,23 @@
+// Package envvarstest provides helpers for tests of code that captures environment changes.
+package envvarstest
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	envvars "github.com/jiyeol-lee/localdev/pkg/internal/env_vars"
+)
+
+// Main runs the tests, or acts as the env dump helper when the shell runs the test binary in place
+// of the localdev binary. Call it from TestMain in packages whose tests capture environment changes.
+func Main(m *testing.M) {
+	if handled, err := envvars.RunDumpEnvHelper(os.Args[1:]); handled {
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
@@ -0,0 +1,48 @@
+package fuzzy
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Scores of the runes of a pattern found in a text.
+const (
+	matchScore       = 1
+	consecutiveBonus = 5
+	wordStartBonus   = 3
+	gapPenalty       = 1
+)
+
+// Match reports whether the runes of pattern appear in text in order, ignoring case and spaces in
+// pattern, and scores the match; a higher score is a better match. Runes that follow each other in
+// text and runes at the start of a word score higher, skipped runes lower. An empty pattern
+// matches every text with a score of 0.
+func Match(pattern, text string) (score int, ok bool) {
+	want := []rune(strings.ToLower(strings.Join(strings.Fields(pattern), "")))
+	if len(want) == 0 {
+		return 0, true
+	}
+	runes := []rune(strings.ToLower(text))
+	matched, last := 0, -1
+	for i, r := range runes {
+		if matched == len(want) {
+			break
+		}
+		if r != want[matched] {
+			continue
+		}
+		score += matchScore
+		switch {
+		case last >= 0 && last == i-1:
+			score += consecutiveBonus
+		case last >= 0:
+			score -= min(i-last-1, 10) * gapPenalty
+		}
+		if i == 0 || !unicode.IsLetter(runes[i-1]) && !unicode.IsDigit(runes[i-1]) {
+			score += wordStartBonus
+		}
+		last = i
+		matched++
+	}
+	return score, matched == len(want)
+}
@@ -0,0 +1,45 @@
+package fuzzy
+
+import "testing"
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		text    string
+		wantOk  bool
+	}{
+		{name: "empty pattern", pattern: "", text: "api start pane", wantOk: true},
+		{name: "subsequence", pattern: "apst", text: "api start pane", wantOk: true},
+		{name: "case and spaces are ignored", pattern: "API  St", text: "api start pane", wantOk: true},
+		{name: "out of order", pattern: "tsa", text: "ast", wantOk: false},
+		{name: "missing rune", pattern: "apz", text: "api start pane", wantOk: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, ok := Match(tt.pattern, tt.text); ok != tt.wantOk {
+				t.Errorf("Match(%q, %q) ok = %v, want %v", tt.pattern, tt.text, ok, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestMatch_Ranking(t *testing.T) {
+	tests := []struct {
+		pattern       string
+		better, worse string
+	}{
+		{pattern: "stop", better: "db stop pane", worse: "db save output to pane"},
+		{pattern: "rd", better: "reset db", worse: "for a good"},
+		{pattern: "git", better: "api open git panel", worse: "api generate invoice template"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			better, ok1 := Match(tt.pattern, tt.better)
+			worse, ok2 := Match(tt.pattern, tt.worse)
+			if !ok1 || !ok2 || better <= worse {
+				t.Errorf("Match(%q) = %d for %q and %d for %q, want the first higher", tt.pattern, better, tt.better, worse, tt.worse)
+			}
+		})
+	}
+}
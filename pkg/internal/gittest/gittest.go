@@ -0,0 +1,59 @@
+// Package gittest provides helpers for tests that need real git repositories.
+package gittest
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Command builds a git command in dir with a deterministic identity and no user config.
+func Command(dir string, args ...string) *exec.Cmd {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(
+		os.Environ(),
+		"GIT_CONFIG_GLOBAL=/dev/null",
+		"GIT_CONFIG_NOSYSTEM=1",
+		"GIT_AUTHOR_NAME=test",
+		"GIT_AUTHOR_EMAIL=test@example.com",
+		"GIT_COMMITTER_NAME=test",
+		"GIT_COMMITTER_EMAIL=test@example.com",
+	)
+	return cmd
+}
+
+// Run runs git in dir, fails the test on error and returns the trimmed output.
+func Run(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	output, err := Command(dir, args...).CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, output)
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// NewRepo creates a repository with a single commit on main. The test is skipped when git is not installed.
+func NewRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := t.TempDir()
+	Run(t, dir, "init", "-q", "-b", "main")
+	Run(t, dir, "commit", "-q", "--allow-empty", "-m", "initial")
+	return dir
+}
+
+// NewRepoWithRemote creates a bare remote and a clone tracking origin/main.
+func NewRepoWithRemote(t *testing.T) (repo, remote string) {
+	t.Helper()
+	seed := NewRepo(t)
+	remote = filepath.Join(t.TempDir(), "remote.git")
+	Run(t, seed, "clone", "-q", "--bare", seed, remote)
+	repo = filepath.Join(t.TempDir(), "repo")
+	Run(t, seed, "clone", "-q", remote, repo)
+	return repo, remote
+}
@@ -0,0 +1,198 @@
+package history
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// MaxEntries is how many commands the history file keeps; the oldest are dropped first.
+const MaxEntries = 1000
+
+// Entry is a command run from a pane.
+type Entry struct {
+	Command string `json:"command"`
+	// Config is the config file of the session the command was run in and Pane the name of the
+	// pane, so panes with the same name in different projects keep their own history.
+	Config string    `json:"config"`
+	Pane   string    `json:"pane"`
+	Time   time.Time `json:"time"`
+}
+
+// Path returns the command history file under the Local Dev cache directory. The file is shared by
+// every config so a search finds the commands of every project.
+func Path() (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cacheDir, "localdev", "command_history.json"), nil
+}
+
+// Store is the command history of the panes of one config. A Store without a path keeps its
+// history in memory only and a nil Store records nothing. It is safe for concurrent use.
+type Store struct {
+	path       string
+	configPath string
+
+	mu      sync.Mutex
+	entries []Entry
+}
+
+// Open loads the history file at path for the sessions started with configPath. A missing file
+// is an empty history.
+func Open(path, configPath string) (*Store, error) {
+	s := &Store{path: path, configPath: configPath}
+	entries, err := load(path)
+	if err != nil {
+		return s, err
+	}
+	s.entries = entries
+	return s, nil
+}
+
+// load reads the entries of the history file at path.
+func load(path string) ([]Entry, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var entries []Entry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("error parsing command history %s: %w", path, err)
+	}
+	return entries, nil
+}
+
+// Add records command as the latest command of the named pane, dropping an earlier copy of it,
+// and saves the history. Entries written by other sessions since the history was loaded are kept.
+// The command is stored as given; callers leave out commands that contain secrets.
+func (s *Store) Add(pane, command string) error {
+	if s == nil || strings.TrimSpace(command) == "" {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	unlock, err := lockFile(s.path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	if entries, err := load(s.path); err == nil && entries != nil {
+		s.entries = entries
+	}
+	s.entries = slices.DeleteFunc(s.entries, func(e Entry) bool {
+		return e.Config == s.configPath && e.Pane == pane && e.Command == command
+	})
+	s.entries = append(s.entries, Entry{
+		Command: command,
+		Config:  s.configPath,
+		Pane:    pane,
+		Time:    time.Now(),
+	})
+	if len(s.entries) > MaxEntries {
+		s.entries = slices.Clone(s.entries[len(s.entries)-MaxEntries:])
+	}
+	return s.save()
+}
+
+// Pane returns the commands of the named pane, oldest first.
+func (s *Store) Pane(pane string) []string {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var commands []string
+	for _, e := range s.entries {
+		if e.Config == s.configPath && e.Pane == pane {
+			commands = append(commands, e.Command)
+		}
+	}
+	return commands
+}
+
+// Search returns the distinct commands containing query, newest first. The commands of the named
+// pane come before those of other panes and projects.
+func (s *Store) Search(pane, query string) []string {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var own, others []string
+	seen := map[string]bool{}
+	for _, e := range slices.Backward(s.entries) {
+		if seen[e.Command] || !strings.Contains(e.Command, query) {
+			continue
+		}
+		seen[e.Command] = true
+		if e.Config == s.configPath && e.Pane == pane {
+			own = append(own, e.Command)
+		} else {
+			others = append(others, e.Command)
+		}
+	}
+	return append(own, others...)
+}
+
+// lockFile takes an exclusive lock on the history file at path, shared with every other session, so
+// concurrent Add calls do not drop each other's entries. The returned function releases it.
+func lockFile(path string) (func(), error) {
+	if path == "" {
+		return func() {}, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path+".lock", os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	if err := unix.Flock(int(f.Fd()), unix.LOCK_EX); err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("error locking %s: %w", path, err)
+	}
+	return func() { _ = f.Close() }, nil
+}
+
+// save atomically writes the history file so a crash never leaves a truncated one behind.
+func (s *Store) save() error {
+	if s.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(s.entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".tmp-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
@@ -0,0 +1,131 @@
+package history
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestPath(t *testing.T) {
+	cacheDir := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", cacheDir)
+	t.Setenv("HOME", cacheDir)
+
+	path, err := Path()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(path, cacheDir) || filepath.Ext(path) != ".json" {
+		t.Errorf("Path() = %q, want a .json file under %q", path, cacheDir)
+	}
+}
+
+func TestStore_PersistsPerPaneHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "localdev", "command_history.json")
+	s, err := Open(path, "/project/a.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, add := range []struct{ pane, command string }{
+		{"api", "make test"},
+		{"api", "make lint"},
+		{"web", "npm test"},
+		{"api", "make test"},
+		{"api", "  "},
+	} {
+		if err := s.Add(add.pane, add.command); err != nil {
+			t.Fatal(err)
+		}
+	}
+	other, _ := Open(path, "/project/b.yml")
+	if err := other.Add("api", "go test ./..."); err != nil {
+		t.Fatal(err)
+	}
+
+	// A restart reads what both sessions wrote.
+	reopened, err := Open(path, "/project/a.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := reopened.Pane("api"), []string{"make lint", "make test"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Pane(api) = %v, want %v", got, want)
+	}
+	if got, want := reopened.Search("api", "test"), []string{"make test", "go test ./...", "npm test"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Search(api, test) = %v, want %v", got, want)
+	}
+	if got := reopened.Search("api", "deploy"); got != nil {
+		t.Errorf("Search(api, deploy) = %v, want none", got)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("history file mode = %v, %v, want 0600", info, err)
+	}
+}
+
+func TestStore_ConcurrentSessionsKeepEveryEntry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "command_history.json")
+	var wg sync.WaitGroup
+	for i := range 16 {
+		// Each store stands for another session sharing the history file.
+		s, err := Open(path, fmt.Sprintf("/project/%d.yml", i))
+		if err != nil {
+			t.Fatal(err)
+		}
+		wg.Go(func() {
+			for j := range 20 {
+				if err := s.Add("api", fmt.Sprintf("make %d", j)); err != nil {
+					t.Error(err)
+				}
+			}
+		})
+	}
+	wg.Wait()
+
+	entries, err := load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 16*20 {
+		t.Errorf("history has %d entries, want %d", len(entries), 16*20)
+	}
+}
+
+func TestStore_KeepsMaxEntries(t *testing.T) {
+	s, _ := Open("", "/project/a.yml")
+	for i := range MaxEntries + 5 {
+		if err := s.Add("api", fmt.Sprintf("echo %d", i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got := s.Pane("api")
+	if len(got) != MaxEntries || got[0] != "echo 5" || got[len(got)-1] != fmt.Sprintf("echo %d", MaxEntries+4) {
+		t.Errorf("Pane(api) has %d entries from %q to %q, want the latest %d", len(got), got[0], got[len(got)-1], MaxEntries)
+	}
+}
+
+func TestStore_Nil(t *testing.T) {
+	var s *Store
+	if err := s.Add("api", "make test"); err != nil {
+		t.Errorf("Add() error = %v", err)
+	}
+	if s.Pane("api") != nil || s.Search("api", "") != nil {
+		t.Error("nil Store returned history")
+	}
+}
+
+func TestOpen_InvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "command_history.json")
+	if err := os.WriteFile(path, []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	s, err := Open(path, "/project/a.yml")
+	if err == nil {
+		t.Fatal("Open() error = nil, want a parse error")
+	}
+	if err := s.Add("api", "make test"); err != nil {
+		t.Errorf("Add() on the returned Store error = %v", err)
+	}
+}
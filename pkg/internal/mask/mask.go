@@ -0,0 +1,93 @@
+package mask
+
+import (
+	"cmp"
+	"maps"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Replacement is the text secrets are replaced with.
+const Replacement = "****"
+
+// minValueLength is the shortest env var value that is masked; shorter values such as "1" or
+// "dev" would mask unrelated text.
+const minValueLength = 4
+
+// Masker replaces known secret values and pattern matches in text. A nil Masker masks nothing.
+// It is safe for concurrent use.
+type Masker struct {
+	patterns []*regexp.Regexp
+
+	mu       sync.RWMutex
+	values   map[string]bool
+	replacer *strings.Replacer
+}
+
+// New creates a Masker that masks every match of the given regular expressions.
+func New(patterns []string) (*Masker, error) {
+	m := &Masker{values: make(map[string]bool)}
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, err
+		}
+		m.patterns = append(m.patterns, re)
+	}
+	return m, nil
+}
+
+// AddValues registers secret values to mask. Values shorter than four characters are ignored.
+func (m *Masker) AddValues(values ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	added := false
+	for _, value := range values {
+		if len(value) >= minValueLength && !m.values[value] {
+			m.values[value] = true
+			added = true
+		}
+	}
+	if !added {
+		return
+	}
+	// Replace longer values first so a secret containing another one is masked whole.
+	sorted := slices.SortedFunc(maps.Keys(m.values), func(a, b string) int {
+		return cmp.Or(cmp.Compare(len(b), len(a)), strings.Compare(a, b))
+	})
+	oldnew := make([]string, 0, 2*len(sorted))
+	for _, value := range sorted {
+		oldnew = append(oldnew, value, Replacement)
+	}
+	m.replacer = strings.NewReplacer(oldnew...)
+}
+
+// Mask returns s with every registered value and pattern match replaced.
+func (m *Masker) Mask(s string) string {
+	if m == nil {
+		return s
+	}
+	m.mu.RLock()
+	replacer := m.replacer
+	m.mu.RUnlock()
+	if replacer != nil {
+		s = replacer.Replace(s)
+	}
+	for _, re := range m.patterns {
+		s = re.ReplaceAllLiteralString(s, Replacement)
+	}
+	return s
+}
+
+// MatchName reports whether the env var name matches any of the glob patterns, ignoring case.
+func MatchName(globs []string, name string) bool {
+	for _, glob := range globs {
+		if ok, _ := path.Match(strings.ToUpper(glob), strings.ToUpper(name)); ok {
+			return true
+		}
+	}
+	return false
+}
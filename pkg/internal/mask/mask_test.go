@@ -0,0 +1,69 @@
+package mask_test
+
+import (
+	"testing"
+
+	"github.com/jiyeol-lee/localdev/pkg/internal/mask"
+)
+
+func TestMasker_Mask(t *testing.T) {
+	m, err := mask.New([]string{`ghp_[A-Za-z0-9]+`, `password=\S+`})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	m.AddValues("s3cr3t-value", "s3cr3t", "abc", "")
+
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "no secrets", in: "server listening on :3000", want: "server listening on :3000"},
+		{name: "registered value", in: "token is s3cr3t.", want: "token is ****."},
+		{name: "longest value wins", in: "using s3cr3t-value", want: "using ****"},
+		{name: "short values are ignored", in: "abc", want: "abc"},
+		{name: "pattern match", in: "auth ghp_AbC123 ok", want: "auth **** ok"},
+		{name: "pattern and value", in: "password=hunter2 s3cr3t", want: "**** ****"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := m.Mask(tt.in); got != tt.want {
+				t.Errorf("Mask(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMasker_NilMasksNothing(t *testing.T) {
+	var m *mask.Masker
+	if got := m.Mask("s3cr3t"); got != "s3cr3t" {
+		t.Errorf("Mask() = %q, want input unchanged", got)
+	}
+}
+
+func TestNew_InvalidPattern(t *testing.T) {
+	if _, err := mask.New([]string{"("}); err == nil {
+		t.Error("New() error = nil, want an invalid pattern error")
+	}
+}
+
+func TestMatchName(t *testing.T) {
+	globs := []string{"*TOKEN*", "DB_*"}
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{name: "GITHUB_TOKEN", want: true},
+		{name: "github_token_path", want: true},
+		{name: "DB_PASSWORD", want: true},
+		{name: "MYDB_HOST", want: false},
+		{name: "PATH", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mask.MatchName(globs, tt.name); got != tt.want {
+				t.Errorf("MatchName(%q) = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
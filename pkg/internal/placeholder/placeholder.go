@@ -0,0 +1,121 @@
+package placeholder
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Kinds of placeholders.
+const (
+	// Input is a free text value: {{input "Label"}} or {{input "Label" "default"}}.
+	Input = "input"
+	// Choice is one of a list of values: {{choice "Label" "first" "second"}}.
+	Choice = "choice"
+)
+
+// placeholderRegex matches the input and choice placeholders. Other {{...}} text, such as a
+// docker --format template, is left alone.
+var placeholderRegex = regexp.MustCompile(`\{\{\s*(input|choice)\b(.*?)\}\}`)
+
+// Field is a value a command asks for before it runs.
+type Field struct {
+	Kind  string
+	Label string
+	// Default is the initial value; for a choice it is the first option.
+	Default string
+	// Options are the values of a choice.
+	Options []string
+}
+
+// Parse returns the fields of the placeholders in the commands, in order of appearance. Placeholders
+// with the same label share one field, defined by the first of them.
+func Parse(commands ...string) ([]Field, error) {
+	var fields []Field
+	seen := map[string]bool{}
+	for _, command := range commands {
+		for _, match := range placeholderRegex.FindAllStringSubmatch(command, -1) {
+			field, err := parseField(match[1], match[2])
+			if err != nil {
+				return nil, fmt.Errorf("placeholder %s: %w", match[0], err)
+			}
+			if seen[field.Label] {
+				continue
+			}
+			seen[field.Label] = true
+			fields = append(fields, field)
+		}
+	}
+	return fields, nil
+}
+
+// parseField parses the quoted arguments of a placeholder of the given kind.
+func parseField(kind, rest string) (Field, error) {
+	args, err := parseArgs(rest)
+	if err != nil {
+		return Field{}, err
+	}
+	if len(args) == 0 || args[0] == "" {
+		return Field{}, fmt.Errorf("missing label")
+	}
+	field := Field{Kind: kind, Label: args[0]}
+	switch kind {
+	case Input:
+		if len(args) > 2 {
+			return Field{}, fmt.Errorf("input takes a label and an optional default")
+		}
+		if len(args) == 2 {
+			field.Default = args[1]
+		}
+	case Choice:
+		if len(args) < 2 {
+			return Field{}, fmt.Errorf("choice needs at least one option")
+		}
+		field.Options = args[1:]
+		field.Default = args[1]
+	}
+	return field, nil
+}
+
+// parseArgs splits space-separated Go string literals such as "Branch name" or `it's`.
+func parseArgs(s string) ([]string, error) {
+	var args []string
+	for s = strings.TrimSpace(s); s != ""; s = strings.TrimSpace(s) {
+		quoted, err := strconv.QuotedPrefix(s)
+		if err != nil {
+			return nil, fmt.Errorf("arguments must be quoted strings, got %s", s)
+		}
+		arg, err := strconv.Unquote(quoted)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+		s = s[len(quoted):]
+		if s != "" && s[0] != ' ' && s[0] != '\t' {
+			return nil, fmt.Errorf("arguments must be separated by spaces")
+		}
+	}
+	return args, nil
+}
+
+// Render replaces the placeholders in command with the shell-quoted values of their labels. A
+// placeholder without a value is replaced with an empty string.
+func Render(command string, values map[string]string) string {
+	return placeholderRegex.ReplaceAllStringFunc(command, func(match string) string {
+		submatch := placeholderRegex.FindStringSubmatch(match)
+		field, err := parseField(submatch[1], submatch[2])
+		if err != nil {
+			return match
+		}
+		return Quote(values[field.Label])
+	})
+}
+
+// Quote quotes s as a single POSIX shell word.
+func Quote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_./:=@%+,") == "" {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,77 @@
+package placeholder
+
+import (
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		commands []string
+		want     []Field
+		wantErr  string
+	}{
+		{name: "no placeholders", commands: []string{"docker ps --format '{{.Names}}'"}},
+		{
+			name:     "input and choice",
+			commands: []string{`git checkout -b {{input "Branch name"}} && deploy {{ choice "env" "dev" "staging" }}`},
+			want: []Field{
+				{Kind: Input, Label: "Branch name"},
+				{Kind: Choice, Label: "env", Default: "dev", Options: []string{"dev", "staging"}},
+			},
+		},
+		{
+			name:     "input default and shared label across commands",
+			commands: []string{`migrate {{input "version" "latest"}}`, `echo {{input "version"}}`},
+			want:     []Field{{Kind: Input, Label: "version", Default: "latest"}},
+		},
+		{name: "missing label", commands: []string{`echo {{input}}`}, wantErr: "missing label"},
+		{name: "unquoted argument", commands: []string{`echo {{input Branch}}`}, wantErr: "quoted strings"},
+		{name: "choice without options", commands: []string{`echo {{choice "env"}}`}, wantErr: "at least one option"},
+		{name: "too many input arguments", commands: []string{`echo {{input "a" "b" "c"}}`}, wantErr: "optional default"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.commands...)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Parse() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	command := `git checkout -b {{input "Branch name"}} && deploy {{choice "env" "dev" "staging"}} {{input "Branch name"}}`
+	got := Render(command, map[string]string{"Branch name": "fix/it's; rm -rf ~", "env": "staging"})
+	want := `git checkout -b 'fix/it'\''s; rm -rf ~' && deploy staging 'fix/it'\''s; rm -rf ~'`
+	if got != want {
+		t.Errorf("Render() = %q, want %q", got, want)
+	}
+}
+
+func TestQuote(t *testing.T) {
+	tests := []string{"", "plain", "two words", "it's", `$HOME "$(id)" ` + "`id`", "a\nb", "*?[]~!#&|;<>(){}\\"}
+	for _, value := range tests {
+		t.Run(value, func(t *testing.T) {
+			out, err := exec.Command("sh", "-c", "printf %s "+Quote(value)).Output()
+			if err != nil {
+				t.Fatalf("sh error = %v", err)
+			}
+			if string(out) != value {
+				t.Errorf("sh printed %q for Quote(%q) = %s", out, value, Quote(value))
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package session
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// LockedError reports that another Local Dev instance holds the lock of a config.
+type LockedError struct {
+	ConfigPath string
+	// PID is the process ID of the owning instance, or 0 when unknown.
+	PID int
+}
+
+func (e *LockedError) Error() string {
+	if e.PID == 0 {
+		return fmt.Sprintf("another Local Dev session is already running with %s", e.ConfigPath)
+	}
+	return fmt.Sprintf(
+		"another Local Dev session (PID %d) is already running with %s",
+		e.PID,
+		e.ConfigPath,
+	)
+}
+
+// Lock is an advisory lock that allows a single Local Dev instance per config.
+// The operating system releases it when the process exits.
+type Lock struct {
+	file *os.File
+}
+
+// AcquireLock locks the sessions started with configPath, the resolved config file path.
+// It returns a *LockedError when another process holds the lock.
+func AcquireLock(configPath string) (*Lock, error) {
+	path, err := sessionFile(configPath, ".lock")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	if err := unix.Flock(int(f.Fd()), unix.LOCK_EX|unix.LOCK_NB); err != nil {
+		defer f.Close()
+		if errors.Is(err, unix.EWOULDBLOCK) {
+			data, _ := os.ReadFile(path)
+			pid, _ := strconv.Atoi(strings.TrimSpace(string(data)))
+			return nil, &LockedError{ConfigPath: configPath, PID: pid}
+		}
+		return nil, fmt.Errorf("error locking %s: %w", path, err)
+	}
+	// Record the owner for the message shown to a second instance.
+	if err := f.Truncate(0); err == nil {
+		_, _ = f.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0)
+	}
+	return &Lock{file: f}, nil
+}
+
+// Release unlocks the lock. A nil Lock is a no-op.
+func (l *Lock) Release() error {
+	if l == nil {
+		return nil
+	}
+	return l.file.Close()
+}
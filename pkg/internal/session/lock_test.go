@@ -0,0 +1,42 @@
+package session
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestAcquireLock(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+
+	lock, err := AcquireLock("/config/config.yml")
+	if err != nil {
+		t.Fatalf("AcquireLock() error = %v", err)
+	}
+
+	_, err = AcquireLock("/config/config.yml")
+	var lockedErr *LockedError
+	if !errors.As(err, &lockedErr) || lockedErr.PID != os.Getpid() {
+		t.Fatalf("second AcquireLock() error = %v, want a LockedError with PID %d", err, os.Getpid())
+	}
+	if !strings.Contains(err.Error(), "/config/config.yml") {
+		t.Errorf("error = %q, want the config path", err)
+	}
+
+	other, err := AcquireLock("/config/other.yml")
+	if err != nil {
+		t.Fatalf("AcquireLock() for another config error = %v", err)
+	}
+	_ = other.Release()
+
+	if err := lock.Release(); err != nil {
+		t.Fatal(err)
+	}
+	lock, err = AcquireLock("/config/config.yml")
+	if err != nil {
+		t.Fatalf("AcquireLock() after Release() error = %v", err)
+	}
+	_ = lock.Release()
+}
@@ -0,0 +1,204 @@
+package session
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// Pane records the start process group of a pane.
+type Pane struct {
+	Name      string    `json:"name"`
+	Pgid      int       `json:"pgid"`
+	StartedAt time.Time `json:"started_at"`
+	// Orphaned is set for a process group left running by an earlier session and carried over
+	// so a later launch can still clean it up.
+	Orphaned bool `json:"orphaned,omitempty"`
+}
+
+// State is the content of a session state file.
+type State struct {
+	// PID is the process ID of the Local Dev instance that owns the session.
+	PID        int       `json:"pid"`
+	ConfigPath string    `json:"config_path"`
+	StartedAt  time.Time `json:"started_at"`
+	Panes      []Pane    `json:"panes"`
+}
+
+// Path returns the state file of the sessions started with the given config file, under the
+// Local Dev cache directory.
+func Path(configPath string) (string, error) {
+	return sessionFile(configPath, ".json")
+}
+
+// sessionFile returns the file with the given extension of the sessions started with configPath.
+func sessionFile(configPath, ext string) (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(configPath))
+	return filepath.Join(cacheDir, "localdev", "sessions", hex.EncodeToString(sum[:8])+ext), nil
+}
+
+// Load reads the state file at path. It returns nil without an error when the file does not exist.
+func Load(path string) (*State, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var state State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("error parsing session state %s: %w", path, err)
+	}
+	return &state, nil
+}
+
+// startTimeTolerance is how far the start time of a process group leader may be from the start
+// time recorded for its pane.
+const startTimeTolerance = 5 * time.Second
+
+// Orphans returns the pane process groups that alive reports as still running, unless the Local Dev
+// instance that recorded them is itself still running. A process group whose leader startTime
+// reports as started at another time than recorded is a new group that reuses the ID and is left
+// out; when startTime fails, e.g. because the leader exited, the group is kept.
+func (s *State) Orphans(alive func(pgid int) bool, startTime func(pid int) (time.Time, error)) []Pane {
+	if s == nil || (s.PID != os.Getpid() && unix.Kill(s.PID, 0) == nil) {
+		return nil
+	}
+	var orphans []Pane
+	for _, pane := range s.Panes {
+		if !alive(pane.Pgid) {
+			continue
+		}
+		if started, err := startTime(pane.Pgid); err == nil &&
+			(started.Before(pane.StartedAt.Add(-startTimeTolerance)) ||
+				started.After(pane.StartedAt.Add(startTimeTolerance))) {
+			continue
+		}
+		orphans = append(orphans, pane)
+	}
+	return orphans
+}
+
+// Recorder keeps the state file of the current session up to date. A nil Recorder records nothing.
+// It is safe for concurrent use.
+type Recorder struct {
+	path string
+
+	mu    sync.Mutex
+	state State
+}
+
+// NewRecorder creates a Recorder that writes the state of a session started with configPath to path.
+func NewRecorder(path, configPath string) *Recorder {
+	return &Recorder{
+		path: path,
+		state: State{
+			PID:        os.Getpid(),
+			ConfigPath: configPath,
+			StartedAt:  time.Now(),
+		},
+	}
+}
+
+// KeepOrphans carries orphans, the process groups an earlier session left running, over to the
+// state of this session, so they are offered for clean up again if they are still running when a
+// later session starts.
+func (r *Recorder) KeepOrphans(orphans []Pane) error {
+	if r == nil || len(orphans) == 0 {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, orphan := range orphans {
+		orphan.Orphaned = true
+		r.state.Panes = append(r.state.Panes, orphan)
+	}
+	return r.save()
+}
+
+// SetPane records pgid as the start process group of the named pane, replacing the previous one.
+func (r *Recorder) SetPane(name string, pgid int) error {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.state.Panes = slices.DeleteFunc(r.state.Panes, func(p Pane) bool {
+		return p.Name == name && !p.Orphaned
+	})
+	r.state.Panes = append(r.state.Panes, Pane{Name: name, Pgid: pgid, StartedAt: time.Now()})
+	return r.save()
+}
+
+// RemovePane forgets the process group pgid of the named pane.
+func (r *Recorder) RemovePane(name string, pgid int) error {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := len(r.state.Panes)
+	r.state.Panes = slices.DeleteFunc(r.state.Panes, func(p Pane) bool {
+		return p.Name == name && p.Pgid == pgid
+	})
+	if len(r.state.Panes) == n {
+		return nil
+	}
+	return r.save()
+}
+
+// Remove deletes the state file once every process group of the session has exited. Orphans
+// carried over from an earlier session are kept in the file instead.
+func (r *Recorder) Remove() error {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.state.Panes = slices.DeleteFunc(r.state.Panes, func(p Pane) bool { return !p.Orphaned })
+	if len(r.state.Panes) > 0 {
+		return r.save()
+	}
+	if err := os.Remove(r.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// save atomically writes the state file so a crash never leaves a truncated one behind.
+func (r *Recorder) save() error {
+	data, err := json.MarshalIndent(r.state, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(r.path), filepath.Base(r.path)+".tmp-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), r.path)
+}
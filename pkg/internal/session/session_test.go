@@ -0,0 +1,167 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPath(t *testing.T) {
+	cacheDir := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", cacheDir)
+	t.Setenv("HOME", cacheDir)
+
+	a, err := Path("/config/a.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := Path("/config/b.yml")
+	if a == b {
+		t.Errorf("Path() = %q for different configs, want distinct files", a)
+	}
+	if again, _ := Path("/config/a.yml"); again != a {
+		t.Errorf("Path() = %q, then %q for the same config", a, again)
+	}
+	if !strings.HasPrefix(a, cacheDir) || filepath.Ext(a) != ".json" {
+		t.Errorf("Path() = %q, want a .json file under %q", a, cacheDir)
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sessions", "state.json")
+	r := NewRecorder(path, "/config/config.yml")
+
+	if err := r.SetPane("api", 100); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.SetPane("web", 200); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.SetPane("api", 300); err != nil {
+		t.Fatal(err)
+	}
+	// A stale process group of a restarted pane is ignored.
+	if err := r.RemovePane("api", 100); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.RemovePane("web", 200); err != nil {
+		t.Fatal(err)
+	}
+
+	state, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.PID != os.Getpid() || state.ConfigPath != "/config/config.yml" {
+		t.Errorf("Load() = %+v, want the current PID and config path", state)
+	}
+	var pgids []int
+	for _, pane := range state.Panes {
+		pgids = append(pgids, pane.Pgid)
+	}
+	if want := []int{300}; !reflect.DeepEqual(pgids, want) {
+		t.Errorf("recorded pgids = %v, want %v", pgids, want)
+	}
+
+	if err := r.Remove(); err != nil {
+		t.Fatal(err)
+	}
+	if state, err := Load(path); state != nil || err != nil {
+		t.Errorf("Load() after Remove() = %+v, %v, want nil, nil", state, err)
+	}
+}
+
+func TestRecorder_KeepOrphans(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	orphan := Pane{Name: "api", Pgid: 100, StartedAt: time.Date(2024, 3, 5, 14, 7, 9, 0, time.UTC)}
+	r := NewRecorder(path, "/config/config.yml")
+
+	if err := r.KeepOrphans([]Pane{orphan}); err != nil {
+		t.Fatal(err)
+	}
+	// The new process group of the pane with the same name leaves the orphan alone.
+	if err := r.SetPane("api", 200); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.RemovePane("api", 200); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Remove(); err != nil {
+		t.Fatal(err)
+	}
+
+	state, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	orphan.Orphaned = true
+	if state == nil || !reflect.DeepEqual(state.Panes, []Pane{orphan}) {
+		t.Fatalf("Load() after Remove() = %+v, want only the orphan", state)
+	}
+}
+
+func TestRecorder_NilIsNoOp(t *testing.T) {
+	var r *Recorder
+	if err := r.SetPane("api", 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.RemovePane("api", 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Remove(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestState_Orphans(t *testing.T) {
+	startedAt := time.Date(2024, 3, 5, 14, 7, 9, 0, time.UTC)
+	panes := []Pane{
+		{Name: "api", Pgid: 100, StartedAt: startedAt},
+		{Name: "web", Pgid: 200, StartedAt: startedAt},
+		{Name: "worker", Pgid: 300, StartedAt: startedAt},
+		{Name: "db", Pgid: 400, StartedAt: startedAt},
+	}
+	alive := func(pgid int) bool { return pgid != 100 }
+	startTime := func(pid int) (time.Time, error) {
+		switch pid {
+		case 200:
+			return startedAt.Add(-time.Second), nil
+		case 300:
+			// The ID was reused by a process group started an hour later.
+			return startedAt.Add(time.Hour), nil
+		default:
+			return time.Time{}, os.ErrNotExist
+		}
+	}
+
+	tests := []struct {
+		name  string
+		state *State
+		want  []string
+	}{
+		{name: "no previous session", state: nil},
+		{
+			name:  "owner exited",
+			state: &State{PID: os.Getpid(), Panes: panes},
+			want:  []string{"web", "db"},
+		},
+		{
+			name:  "owner still running",
+			state: &State{PID: os.Getppid(), Panes: panes},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, orphan := range tt.state.Orphans(alive, startTime) {
+				got = append(got, orphan.Name)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Orphans() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
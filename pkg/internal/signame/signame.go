@@ -0,0 +1,22 @@
+// Package signame converts between signal names and signals.
+package signame
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// Parse converts a signal name such as "SIGTERM", "term" or "TERM" to a signal.
+func Parse(name string) (unix.Signal, error) {
+	upper := strings.ToUpper(strings.TrimSpace(name))
+	if !strings.HasPrefix(upper, "SIG") {
+		upper = "SIG" + upper
+	}
+	signal := unix.SignalNum(upper)
+	if signal == 0 {
+		return 0, fmt.Errorf("unknown signal: %s", name)
+	}
+	return signal, nil
+}
@@ -0,0 +1,33 @@
+package signame
+
+import (
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    unix.Signal
+		wantErr bool
+	}{
+		{name: "SIGTERM", want: unix.SIGTERM},
+		{name: "term", want: unix.SIGTERM},
+		{name: " INT ", want: unix.SIGINT},
+		{name: "SIGKILL", want: unix.SIGKILL},
+		{name: "SIGNOPE", wantErr: true},
+		{name: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Parse() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
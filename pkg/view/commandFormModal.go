@@ -0,0 +1,159 @@
+package view
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/jiyeol-lee/localdev/pkg/constant"
+	"github.com/jiyeol-lee/localdev/pkg/internal/placeholder"
+	"github.com/rivo/tview"
+)
+
+// maxCommandInputHistory is how many previous values are remembered per form field.
+const maxCommandInputHistory = 20
+
+type commandFormModal struct {
+	callerPaneIndex int
+	form            *tview.Form
+	fields          []placeholder.Field
+	onSubmit        func(values map[string]string)
+	// inputHistory holds the submitted values keyed by pane name and field label, oldest first.
+	// It outlives reset so the next form starts from the last values.
+	inputHistory map[string][]string
+}
+
+func newCommandFormModal() *commandFormModal {
+	return &commandFormModal{
+		callerPaneIndex: -1,
+		inputHistory:    map[string][]string{},
+	}
+}
+
+func (c *commandFormModal) reset() {
+	c.callerPaneIndex = -1
+	c.form = nil
+	c.fields = nil
+	c.onSubmit = nil
+}
+
+// historyKey returns the inputHistory key of a field of the named pane.
+func historyKey(paneName, label string) string {
+	return paneName + "\x00" + label
+}
+
+// appendInputHistory records value as the latest value of a field, dropping an older copy of it.
+func (c *commandFormModal) appendInputHistory(key, value string) {
+	history := slices.DeleteFunc(c.inputHistory[key], func(h string) bool { return h == value })
+	history = append(history, value)
+	if len(history) > maxCommandInputHistory {
+		history = history[len(history)-maxCommandInputHistory:]
+	}
+	c.inputHistory[key] = history
+}
+
+func (v *View) checkIsCommandFormModalOpen() bool {
+	return v.tviewPages.HasPage(constant.Page.CommandFormModalPage)
+}
+
+func (v *View) removeCommandFormModal() {
+	v.tviewPages.RemovePage(constant.Page.CommandFormModalPage)
+	v.commandFormModal.reset()
+	v.enablePanesMouse()
+}
+
+// openCommandFormModal asks for the values of the placeholder fields of a command of the pane at
+// index and calls onSubmit with them keyed by label. Each field starts with its last submitted
+// value, or its default; input fields suggest earlier values as you type.
+func (v *View) openCommandFormModal(
+	index int,
+	title string,
+	fields []placeholder.Field,
+	onSubmit func(values map[string]string),
+) {
+	if v.checkIsCommandFormModalOpen() {
+		return
+	}
+	paneName := v.panes[index].config.Name
+	form := tview.NewForm()
+	for _, field := range fields {
+		history := v.commandFormModal.inputHistory[historyKey(paneName, field.Label)]
+		initial := field.Default
+		if len(history) > 0 {
+			initial = history[len(history)-1]
+		}
+		switch field.Kind {
+		case placeholder.Choice:
+			form.AddDropDown(field.Label, field.Options, max(slices.Index(field.Options, initial), 0), nil)
+		default:
+			inputField := tview.NewInputField().SetLabel(field.Label).SetText(initial)
+			inputField.SetAutocompleteFunc(func(text string) []string {
+				var entries []string
+				for _, h := range slices.Backward(history) {
+					if h != text && strings.Contains(h, text) {
+						entries = append(entries, h)
+					}
+				}
+				return entries
+			})
+			form.AddFormItem(inputField)
+		}
+	}
+	form.AddButton("Run", v.submitCommandForm).
+		AddButton("Cancel", func() {
+			v.removeCommandFormModal()
+			v.tviewApp.SetFocus(v.panes[index].textView)
+		}).
+		SetCancelFunc(func() {
+			v.removeCommandFormModal()
+			v.tviewApp.SetFocus(v.panes[index].textView)
+		})
+	form.SetBorder(true).SetTitle(title)
+
+	modal := func(p tview.Primitive) *tview.Grid {
+		g := tview.NewGrid()
+		g.SetDrawFunc(func(_ tcell.Screen, x, y, width, height int) (int, int, int, int) {
+			if width > 90 {
+				g.SetColumns(0, 80, 0)
+			} else {
+				g.SetColumns(2, 0, 2)
+			}
+			return x, y, width, height
+		})
+		return g.
+			SetColumns(2, 0, 2).
+			SetRows(0, 2*len(fields)+5, 0).
+			AddItem(p, 1, 1, 1, 1, 0, 0, true)
+	}
+
+	v.commandFormModal.callerPaneIndex = index
+	v.commandFormModal.form = form
+	v.commandFormModal.fields = fields
+	v.commandFormModal.onSubmit = onSubmit
+	v.tviewPages.AddPage(constant.Page.CommandFormModalPage, modal(form), true, true)
+	v.disablePanesMouse()
+}
+
+// submitCommandForm records the values of the open command form, closes it and passes the values
+// on.
+func (v *View) submitCommandForm() {
+	c := v.commandFormModal
+	index, onSubmit := c.callerPaneIndex, c.onSubmit
+	paneName := v.panes[index].config.Name
+	values := map[string]string{}
+	for i, field := range c.fields {
+		var value string
+		switch item := c.form.GetFormItem(i).(type) {
+		case *tview.DropDown:
+			_, value = item.GetCurrentOption()
+		case *tview.InputField:
+			value = item.GetText()
+		}
+		values[field.Label] = value
+		c.appendInputHistory(historyKey(paneName, field.Label), value)
+	}
+
+	v.removeCommandFormModal()
+	v.tviewApp.SetFocus(v.panes[index].textView)
+	onSubmit(values)
+}
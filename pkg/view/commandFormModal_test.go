@@ -0,0 +1,86 @@
+package view
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jiyeol-lee/localdev/pkg/config"
+	"github.com/jiyeol-lee/localdev/pkg/constant"
+	"github.com/jiyeol-lee/localdev/pkg/internal/placeholder"
+	"github.com/rivo/tview"
+)
+
+func TestView_commandFormModal_SubmitsValuesAndRemembersThem(t *testing.T) {
+	app := startTestTviewApplication(t)
+	p := &Pane{textView: tview.NewTextView(), config: config.ConfigPane{Name: "api"}}
+	pages := tview.NewPages().AddPage(constant.Page.MainPage, p.textView, true, true)
+	v := &View{
+		tviewApp:         app,
+		tviewPages:       pages,
+		panes:            []*Pane{p},
+		commandFormModal: newCommandFormModal(),
+	}
+	fields := []placeholder.Field{
+		{Kind: placeholder.Input, Label: "Branch name", Default: "main"},
+		{Kind: placeholder.Choice, Label: "env", Default: "dev", Options: []string{"dev", "staging"}},
+	}
+
+	var got map[string]string
+	var initialBranch, initialEnv string
+	app.QueueUpdate(func() {
+		app.SetRoot(pages, true)
+		v.openCommandFormModal(0, "Deploy", fields, func(values map[string]string) { got = values })
+		form := v.commandFormModal.form
+		form.GetFormItem(0).(*tview.InputField).SetText("feature/x")
+		form.GetFormItem(1).(*tview.DropDown).SetCurrentOption(1)
+		v.submitCommandForm()
+
+		// The next form starts from the submitted values.
+		v.openCommandFormModal(0, "Deploy", fields, func(map[string]string) {})
+		form = v.commandFormModal.form
+		initialBranch = form.GetFormItem(0).(*tview.InputField).GetText()
+		_, initialEnv = form.GetFormItem(1).(*tview.DropDown).GetCurrentOption()
+	})
+
+	want := map[string]string{"Branch name": "feature/x", "env": "staging"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("submitted values = %v, want %v", got, want)
+	}
+	if initialBranch != "feature/x" || initialEnv != "staging" {
+		t.Errorf("reopened form = %q, %q, want the last submitted values", initialBranch, initialEnv)
+	}
+}
+
+func Test_commandFormModal_appendInputHistory(t *testing.T) {
+	c := newCommandFormModal()
+	for _, value := range []string{"a", "b", "a"} {
+		c.appendInputHistory("key", value)
+	}
+	for i := range maxCommandInputHistory {
+		c.appendInputHistory("full", string(rune('a'+i)))
+	}
+	c.appendInputHistory("full", "new")
+
+	if got, want := c.inputHistory["key"], []string{"b", "a"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("history = %v, want %v", got, want)
+	}
+	full := c.inputHistory["full"]
+	if len(full) != maxCommandInputHistory || full[0] != "b" || full[len(full)-1] != "new" {
+		t.Errorf("history = %v, want the latest %d values", full, maxCommandInputHistory)
+	}
+}
+
+func Test_renderConfigCommand(t *testing.T) {
+	cc := &config.ConfigCommand{
+		Steps:   []string{"<stop_pane>", `make db-reset DB={{input "db"}}`, "<start_pane>"},
+		Confirm: "Reset?",
+	}
+	got := renderConfigCommand(cc, map[string]string{"db": "my db"})
+	want := []string{"<stop_pane>", "make db-reset DB='my db'", "<start_pane>"}
+	if !reflect.DeepEqual(got.Steps, want) || got.Confirm != "Reset?" {
+		t.Errorf("renderConfigCommand() = %+v, want steps %v", got, want)
+	}
+	if cc.Steps[1] != `make db-reset DB={{input "db"}}` {
+		t.Errorf("renderConfigCommand() changed the configured steps: %v", cc.Steps)
+	}
+}
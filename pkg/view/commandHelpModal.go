@@ -1,18 +1,266 @@
 package view
 
-import "github.com/rivo/tview"
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/jiyeol-lee/localdev/internal/logger"
+	"github.com/jiyeol-lee/localdev/pkg/config"
+	"github.com/jiyeol-lee/localdev/pkg/constant"
+	"github.com/jiyeol-lee/localdev/pkg/internal/fuzzy"
+	"github.com/jiyeol-lee/localdev/pkg/util"
+	"github.com/rivo/tview"
+)
 
 type commandHelpModal struct {
 	callerPaneIndex int
-	textView        *tview.TextView
+	filterField     *tview.InputField
+	table           *tview.Table
+	// textView shows the schedule and environment summaries below the commands.
+	textView *tview.TextView
+	commands []boundCommand
+	// matches are the commands matching the filter, in the order of the table rows.
+	matches []boundCommand
 }
 
 func newCommandHelpModal() *commandHelpModal {
 	return &commandHelpModal{
-		textView: tview.NewTextView(),
+		callerPaneIndex: -1,
 	}
 }
 
 func (c *commandHelpModal) reset() {
+	c.callerPaneIndex = -1
+	c.filterField = nil
+	c.table = nil
 	c.textView = nil
+	c.commands = nil
+	c.matches = nil
+}
+
+// boundCommand is a configured command of a pane with the key it is bound to.
+type boundCommand struct {
+	key     string
+	command *config.ConfigCommand
+}
+
+// commandText returns the command string, or the steps of the command.
+func (b boundCommand) commandText() string {
+	if len(b.command.Steps) > 0 {
+		return strings.Join(b.command.Steps, " → ")
+	}
+	return b.command.Command
+}
+
+// flags returns the options set on the command.
+func (b boundCommand) flags() []string {
+	var flags []string
+	if isReservedCommand(b.command.Command) {
+		flags = append(flags, "reserved")
+	}
+	if b.command.Silent {
+		flags = append(flags, "silent")
+	}
+	if b.command.AutoExecute {
+		flags = append(flags, "autoExecute")
+	}
+	if len(b.command.Steps) > 0 {
+		flags = append(flags, "steps")
+	}
+	if b.command.Confirm != "" {
+		flags = append(flags, "confirm")
+	}
+	return flags
+}
+
+// paneCommands returns the configured commands of the pane at index sorted by key, a lowercase key
+// before its uppercase one.
+func (v *View) paneCommands(index int) []boundCommand {
+	p := v.panes[index]
+	if p.config.Commands == nil {
+		return nil
+	}
+	paneCommandsMap, err := util.YamlToMap[*config.ConfigCommands, *config.ConfigCommand](
+		p.config.Commands,
+	)
+	if err != nil {
+		logger.Errorf("error listing commands of pane %s: %v", p.config.Name, err)
+		return nil
+	}
+	var commands []boundCommand
+	for key, configCommand := range paneCommandsMap {
+		if configCommand == nil || configCommand.Command == "" && len(configCommand.Steps) == 0 {
+			continue
+		}
+		c, err := convertCommandKeyToCharacter(key)
+		if err != nil {
+			logger.Errorf("error listing command key %q of pane %s: %v", key, p.config.Name, err)
+			continue
+		}
+		commands = append(commands, boundCommand{key: c, command: configCommand})
+	}
+	slices.SortFunc(commands, func(a, b boundCommand) int {
+		return cmp.Or(
+			cmp.Compare(strings.ToLower(a.key), strings.ToLower(b.key)),
+			cmp.Compare(b.key, a.key),
+		)
+	})
+	return commands
+}
+
+// helpCommands returns the commands listed in the help modal of the pane at index: its shell
+// commands followed by its reserved commands, each sorted by key.
+func (v *View) helpCommands(index int) []boundCommand {
+	commands := v.paneCommands(index)
+	slices.SortStableFunc(commands, func(a, b boundCommand) int {
+		return cmp.Compare(
+			boolToInt(isReservedCommand(a.command.Command)),
+			boolToInt(isReservedCommand(b.command.Command)),
+		)
+	})
+	return commands
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// openCommandHelpModal lists the commands of the pane at index with their key, description, flags
+// and command string, followed by the pane schedule and the project environment changes. Typing
+// filters the commands, Up and Down select one, Enter runs it and Esc closes the modal.
+func (v *View) openCommandHelpModal(index int) {
+	c := v.commandHelpModal
+	c.callerPaneIndex = index
+	c.commands = v.helpCommands(index)
+
+	table := tview.NewTable().SetSelectable(true, false).SetFixed(1, 0)
+	table.SetBorder(true).SetTitle(fmt.Sprintf("Command Help - %s", v.panes[index].config.Name))
+	filterField := tview.NewInputField().
+		SetLabel("Filter: ").
+		SetFieldWidth(0).
+		SetFieldBackgroundColor(tcell.ColorBlack)
+	filterField.SetBorder(true)
+	filterField.SetChangedFunc(func(text string) {
+		v.filterCommandHelp(text)
+	})
+	filterField.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEsc:
+			v.removeCommandHelpModal()
+			v.tviewApp.SetFocus(v.panes[index].textView)
+			return nil
+		case tcell.KeyUp:
+			if row, _ := table.GetSelection(); row > 1 {
+				table.Select(row-1, 0)
+			}
+			return nil
+		case tcell.KeyDown:
+			if row, _ := table.GetSelection(); row < len(c.matches) {
+				table.Select(row+1, 0)
+			}
+			return nil
+		case tcell.KeyEnter:
+			v.runSelectedHelpCommand()
+			return nil
+		}
+		return event
+	})
+
+	textView := tview.NewTextView().SetDynamicColors(true)
+	v.writeScheduleSummary(textView, v.panes[index])
+	v.writeEnvChangesSummary(textView)
+
+	layout := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(filterField, 3, 0, true).
+		AddItem(table, 0, 2, false)
+	if summary := textView.GetText(false); summary != "" {
+		textView.SetBorder(true)
+		layout.AddItem(textView, min(strings.Count(summary, "\n")+2, 12), 0, false)
+	}
+	modal := func(p tview.Primitive) *tview.Grid {
+		g := tview.NewGrid()
+		g.SetDrawFunc(func(_ tcell.Screen, x, y, width, height int) (int, int, int, int) {
+			if width > 110 {
+				g.SetColumns(0, 100, 0)
+			} else {
+				g.SetColumns(2, 0, 2)
+			}
+			return x, y, width, height
+		})
+
+		return g.
+			SetRows(2, 0, 2).
+			AddItem(p, 1, 1, 1, 1, 0, 0, true)
+	}
+
+	c.filterField = filterField
+	c.table = table
+	c.textView = textView
+	v.filterCommandHelp("")
+	v.tviewPages.AddPage(constant.Page.CommandHelpModalPage, modal(layout), true, true)
+	v.disablePanesMouse()
+}
+
+// filterCommandHelp lists the commands of the help modal that fuzzy-match query.
+func (v *View) filterCommandHelp(query string) {
+	c := v.commandHelpModal
+	c.matches = nil
+	for _, command := range c.commands {
+		text := strings.Join([]string{command.key, command.command.Description, command.commandText()}, " ")
+		if _, ok := fuzzy.Match(query, text); ok {
+			c.matches = append(c.matches, command)
+		}
+	}
+
+	c.table.Clear()
+	for column, header := range []string{"Key", "Description", "Flags", "Command"} {
+		c.table.SetCell(0, column, tview.NewTableCell(header).
+			SetTextColor(tcell.ColorOrange).
+			SetSelectable(false))
+	}
+	if len(c.matches) == 0 {
+		message := "No commands available"
+		if len(c.commands) > 0 {
+			message = "No matching commands"
+		}
+		c.table.SetCell(1, 1, tview.NewTableCell(message).SetSelectable(false))
+		return
+	}
+	for i, command := range c.matches {
+		keyColor := tcell.ColorGreen
+		switch {
+		case isReservedCommand(command.command.Command):
+			keyColor = tcell.ColorOrange
+		case command.command.Silent:
+			keyColor = tcell.ColorLightGreen
+		}
+		row := i + 1
+		c.table.SetCell(row, 0, tview.NewTableCell(tview.Escape(command.key)).SetTextColor(keyColor))
+		c.table.SetCell(row, 1, tview.NewTableCell(tview.Escape(command.command.Description)))
+		c.table.SetCell(row, 2, tview.NewTableCell(strings.Join(command.flags(), ",")).
+			SetTextColor(tcell.ColorGray))
+		c.table.SetCell(row, 3, tview.NewTableCell(tview.Escape(command.commandText())).
+			SetExpansion(1).
+			SetMaxWidth(60))
+	}
+	c.table.Select(1, 0).ScrollToBeginning()
+}
+
+// runSelectedHelpCommand closes the help modal and runs the selected command in its pane.
+func (v *View) runSelectedHelpCommand() {
+	c := v.commandHelpModal
+	row, _ := c.table.GetSelection()
+	if row < 1 || row > len(c.matches) {
+		return
+	}
+	index, command := c.callerPaneIndex, c.matches[row-1].command
+	v.removeCommandHelpModal()
+	v.tviewApp.SetFocus(v.panes[index].textView)
+	v.prepareConfigCommand(index, command)
 }
@@ -0,0 +1,108 @@
+package view
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/jiyeol-lee/localdev/pkg/config"
+	"github.com/jiyeol-lee/localdev/pkg/constant"
+	"github.com/rivo/tview"
+)
+
+func newTestHelpPane() *Pane {
+	return &Pane{
+		textView: tview.NewTextView(),
+		config: config.ConfigPane{
+			Name: "api",
+			Commands: &config.ConfigCommands{
+				LowerX: &config.ConfigCommand{Command: "<stop_pane>", Description: "Stop pane"},
+				LowerC: &config.ConfigCommand{Command: "<clear_pane>", Description: "Clear pane"},
+				UpperL: &config.ConfigCommand{Command: "make lint-fix", Description: "Fix lint", AutoExecute: true},
+				LowerL: &config.ConfigCommand{Command: "make lint", Description: "Lint", Silent: true},
+				LowerD: &config.ConfigCommand{
+					Steps:       []string{"<stop_pane>", "make db-reset"},
+					Description: "Reset database",
+					Confirm:     "Drop the database?",
+				},
+			},
+			Schedule: []config.ScheduledCommand{{Command: "make token", Every: time.Minute}},
+		},
+	}
+}
+
+func TestView_helpCommands(t *testing.T) {
+	v := &View{panes: []*Pane{newTestHelpPane()}}
+
+	var got []string
+	for _, command := range v.helpCommands(0) {
+		got = append(got, command.key+"|"+strings.Join(command.flags(), ",")+"|"+command.commandText())
+	}
+	want := []string{
+		"d|steps,confirm|<stop_pane> → make db-reset",
+		"l|silent|make lint",
+		"L|autoExecute|make lint-fix",
+		"c|reserved|<clear_pane>",
+		"x|reserved|<stop_pane>",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("helpCommands() = %v, want %v", got, want)
+	}
+}
+
+func TestView_commandHelpModal_FiltersAndRunsSelection(t *testing.T) {
+	app := startTestTviewApplication(t)
+	p := newTestHelpPane()
+	p.scheduled = newScheduledCommands(p.config.Name, p.config.Schedule)
+	pages := tview.NewPages().AddPage(constant.Page.MainPage, p.textView, true, true)
+	v := &View{
+		tviewApp:         app,
+		tviewPages:       pages,
+		panes:            []*Pane{p},
+		commandHelpModal: newCommandHelpModal(),
+	}
+	// The grid of the modal passes keys on only once it has been drawn.
+	app.QueueUpdateDraw(func() {
+		app.SetRoot(pages, true)
+		p.history.append("old output")
+		v.openCommandHelpModal(0)
+	})
+
+	var rows int
+	var summary string
+	app.QueueUpdate(func() {
+		rows = v.commandHelpModal.table.GetRowCount()
+		summary = v.commandHelpModal.textView.GetText(true)
+	})
+	if rows != 6 {
+		t.Errorf("table has %d rows, want a header and 5 commands", rows)
+	}
+	if !strings.Contains(summary, "===Schedule===") {
+		t.Errorf("summary = %q, want the pane schedule", summary)
+	}
+
+	for _, r := range "clear" {
+		app.QueueEvent(tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone))
+	}
+	waitForUI(t, app, func() bool {
+		return v.commandHelpModal.filterField.GetText() == "clear"
+	})
+	var selected string
+	app.QueueUpdate(func() {
+		row, _ := v.commandHelpModal.table.GetSelection()
+		selected = v.commandHelpModal.table.GetCell(row, 3).Text
+	})
+	if selected != "<clear_pane>" {
+		t.Errorf("selected command = %q, want <clear_pane>", selected)
+	}
+
+	app.QueueEvent(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone))
+	waitForUI(t, app, func() bool {
+		return !v.checkIsCommandHelpModalOpen() && p.textView.HasFocus()
+	})
+	if got := p.history.text(); got != "" {
+		t.Errorf("history = %q, want it cleared", got)
+	}
+}
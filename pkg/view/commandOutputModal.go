@@ -1,13 +1,31 @@
 package view
 
-import "github.com/rivo/tview"
+import (
+	"fmt"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/jiyeol-lee/localdev/internal/logger"
+	"github.com/rivo/tview"
+)
+
+// commandOutputModalTitle is the title of the command input outside of a history search.
+const commandOutputModalTitle = "Command to run"
 
 type commandOutputModal struct {
 	callerPaneIndex     int
 	textView            *tview.TextView
 	inputField          *tview.InputField
 	commandHistoryIndex int
-	commandHistory      []string
+	// commandHistory holds the commands of the caller pane, oldest first, loaded when the modal
+	// opens.
+	commandHistory []string
+
+	// searching is set during a Ctrl+R history search for searchQuery; searchIndex selects among
+	// the matches, newest first, and searchOriginal is the text to restore when it is canceled.
+	searching      bool
+	searchQuery    string
+	searchIndex    int
+	searchOriginal string
 }
 
 func newCommandOutputModal() *commandOutputModal {
@@ -38,6 +56,14 @@ func (c *commandOutputModal) reset() {
 	c.textView = nil
 	c.inputField = nil
 	c.resetCommandHistory()
+	c.endSearch()
+}
+
+func (c *commandOutputModal) endSearch() {
+	c.searching = false
+	c.searchQuery = ""
+	c.searchIndex = 0
+	c.searchOriginal = ""
 }
 
 func (c *commandOutputModal) previousCommand() string {
@@ -65,3 +91,69 @@ func (c *commandOutputModal) nextCommand() string {
 	}
 	return c.commandHistory[c.commandHistoryIndex]
 }
+
+// recordCommand adds command to the history of the pane at index, in the open modal and in the
+// history file. A command that contains a secret is kept out of the history file, which is not
+// masked.
+func (v *View) recordCommand(index int, command string) {
+	v.commandOutputModal.appendCommandHistory(command)
+	if v.MaskSecrets(command) != command {
+		return
+	}
+	if err := v.commandHistory.Add(v.panes[index].config.Name, command); err != nil {
+		logger.Warnf("error saving command history: %v", err)
+	}
+}
+
+// startCommandSearch starts an incremental search of the command history, like Ctrl+R in a shell.
+func (v *View) startCommandSearch(inputField *tview.InputField) {
+	c := v.commandOutputModal
+	c.endSearch()
+	c.searching = true
+	c.searchOriginal = inputField.GetText()
+	v.updateCommandSearch(inputField)
+}
+
+// handleCommandSearchKey handles a key during a history search. Typing refines the query, Ctrl+R
+// moves to the next older match and Esc or Ctrl+G restores the text from before the search. Any
+// other key, such as Enter, keeps the match and is then handled as usual.
+func (v *View) handleCommandSearchKey(inputField *tview.InputField, event *tcell.EventKey) *tcell.EventKey {
+	c := v.commandOutputModal
+	switch event.Key() {
+	case tcell.KeyCtrlR:
+		c.searchIndex++
+	case tcell.KeyRune:
+		c.searchQuery += string(event.Rune())
+		c.searchIndex = 0
+	case tcell.KeyBackspace, tcell.KeyBackspace2:
+		if query := []rune(c.searchQuery); len(query) > 0 {
+			c.searchQuery = string(query[:len(query)-1])
+		}
+		c.searchIndex = 0
+	case tcell.KeyEsc, tcell.KeyCtrlG:
+		inputField.SetText(c.searchOriginal)
+		c.endSearch()
+		inputField.SetTitle(commandOutputModalTitle)
+		return nil
+	default:
+		c.endSearch()
+		inputField.SetTitle(commandOutputModalTitle)
+		return event
+	}
+	v.updateCommandSearch(inputField)
+	return nil
+}
+
+// updateCommandSearch shows the selected match of the history search in inputField. Matches of the
+// caller pane come first, newest first, followed by those of other panes and projects.
+func (v *View) updateCommandSearch(inputField *tview.InputField) {
+	c := v.commandOutputModal
+	matches := v.commandHistory.Search(v.panes[c.callerPaneIndex].config.Name, c.searchQuery)
+	if len(matches) == 0 {
+		inputField.SetTitle(fmt.Sprintf("(failing reverse-i-search)`%s'", tview.Escape(c.searchQuery)))
+		return
+	}
+	c.searchIndex = min(c.searchIndex, len(matches)-1)
+	inputField.SetText(matches[c.searchIndex])
+	inputField.SetTitle(fmt.Sprintf("(reverse-i-search)`%s'", tview.Escape(c.searchQuery)))
+}
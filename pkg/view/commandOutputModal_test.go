@@ -0,0 +1,116 @@
+package view
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/jiyeol-lee/localdev/pkg/config"
+	"github.com/jiyeol-lee/localdev/pkg/constant"
+	"github.com/jiyeol-lee/localdev/pkg/internal/history"
+	"github.com/jiyeol-lee/localdev/pkg/internal/mask"
+	"github.com/rivo/tview"
+)
+
+func TestView_commandOutputModal_HistoryAndSearch(t *testing.T) {
+	store, err := history.Open(filepath.Join(t.TempDir(), "command_history.json"), "/project/a.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, add := range []struct{ pane, command string }{
+		{"api", "make lint"},
+		{"web", "npm run lint"},
+		{"api", "make test"},
+	} {
+		if err := store.Add(add.pane, add.command); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	app := startTestTviewApplication(t)
+	p := &Pane{textView: tview.NewTextView(), config: config.ConfigPane{Name: "api"}}
+	pages := tview.NewPages().AddPage(constant.Page.MainPage, p.textView, true, true)
+	v := &View{
+		tviewApp:           app,
+		tviewPages:         pages,
+		panes:              []*Pane{p},
+		commandOutputModal: newCommandOutputModal(),
+		commandHistory:     store,
+	}
+	var inputField *tview.InputField
+	// The grid of the modal passes keys on only once it has been drawn.
+	app.QueueUpdateDraw(func() {
+		app.SetRoot(pages, true)
+		v.commandOutputModal.callerPaneIndex = 0
+		inputField = v.openCommandOutputModal()
+		inputField.SetText("draft")
+	})
+
+	tests := []struct {
+		name      string
+		key       tcell.Key
+		runes     string
+		wantText  string
+		wantTitle string
+	}{
+		{name: "up shows the latest command of the pane", key: tcell.KeyUp, wantText: "make test", wantTitle: commandOutputModalTitle},
+		{name: "ctrl+r starts a search", key: tcell.KeyCtrlR, wantText: "make test", wantTitle: "(reverse-i-search)`'"},
+		{name: "typing refines the search", runes: "lint", wantText: "make lint", wantTitle: "(reverse-i-search)`lint'"},
+		{name: "ctrl+r moves to other panes", key: tcell.KeyCtrlR, wantText: "npm run lint", wantTitle: "(reverse-i-search)`lint'"},
+		{name: "no match keeps the text", runes: "x", wantText: "npm run lint", wantTitle: "(failing reverse-i-search)`lintx'"},
+		{name: "esc restores the text", key: tcell.KeyEsc, wantText: "make test", wantTitle: commandOutputModalTitle},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.runes != "" {
+				for _, r := range tt.runes {
+					app.QueueEvent(tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone))
+				}
+			} else {
+				app.QueueEvent(tcell.NewEventKey(tt.key, 0, tcell.ModNone))
+			}
+			waitForUI(t, app, func() bool {
+				return inputField.GetText() == tt.wantText && inputField.GetTitle() == tt.wantTitle
+			})
+		})
+	}
+
+	var open bool
+	app.QueueUpdate(func() { open = v.checkIsCommandOutputModalOpen() })
+	if !open {
+		t.Error("Esc during a search closed the modal")
+	}
+}
+
+func TestView_recordCommand_KeepsSecretsOutOfHistoryFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "command_history.json")
+	store, err := history.Open(path, "/project/a.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	masker, err := mask.New([]string{`ghp_[A-Za-z0-9]+`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := &View{
+		panes:              []*Pane{{textView: tview.NewTextView(), config: config.ConfigPane{Name: "api"}}},
+		masker:             masker,
+		commandOutputModal: newCommandOutputModal(),
+		commandHistory:     store,
+	}
+
+	v.recordCommand(0, "make test")
+	v.recordCommand(0, "curl -H 'Authorization: ghp_abc123' localhost")
+
+	reopened, err := history.Open(path, "/project/a.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := reopened.Pane("api"), []string{"make test"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("history file commands = %v, want %v", got, want)
+	}
+	if got := len(v.commandOutputModal.commandHistory); got != 2 {
+		t.Errorf("modal history has %d commands, want 2", got)
+	}
+}
@@ -0,0 +1,235 @@
+package view
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/jiyeol-lee/localdev/pkg/constant"
+	"github.com/jiyeol-lee/localdev/pkg/internal/fuzzy"
+	"github.com/rivo/tview"
+)
+
+// paletteItem is an entry of the command palette: a configured command or a built-in action of a
+// pane.
+type paletteItem struct {
+	paneIndex int
+	// key is the key that runs the item from the pane, if any.
+	key   string
+	label string
+	run   func()
+}
+
+// searchText is the text the palette query is matched against.
+func (p paletteItem) searchText(paneName string) string {
+	return strings.Join([]string{paneName, p.key, p.label}, " ")
+}
+
+type commandPaletteModal struct {
+	callerPaneIndex int
+	inputField      *tview.InputField
+	list            *tview.List
+	items           []paletteItem
+	// matches are the items matching the query, best first, in the order of list.
+	matches []paletteItem
+}
+
+func newCommandPaletteModal() *commandPaletteModal {
+	return &commandPaletteModal{
+		callerPaneIndex: -1,
+	}
+}
+
+func (c *commandPaletteModal) reset() {
+	c.callerPaneIndex = -1
+	c.inputField = nil
+	c.list = nil
+	c.items = nil
+	c.matches = nil
+}
+
+func (v *View) checkIsCommandPaletteModalOpen() bool {
+	return v.tviewPages.HasPage(constant.Page.CommandPaletteModalPage)
+}
+
+func (v *View) removeCommandPaletteModal() {
+	v.tviewPages.RemovePage(constant.Page.CommandPaletteModalPage)
+	v.commandPaletteModal.reset()
+	v.enablePanesMouse()
+}
+
+// paletteItems returns the configured commands and the built-in actions of every pane, those of
+// the pane at first listed first.
+func (v *View) paletteItems(first int) []paletteItem {
+	order := []int{first}
+	for i := range v.panes {
+		if i != first {
+			order = append(order, i)
+		}
+	}
+	var items []paletteItem
+	for _, i := range order {
+		items = append(items, v.paneCommandPaletteItems(i)...)
+
+		focusKey := ""
+		if i < 10 {
+			focusKey = fmt.Sprint((i + 1) % 10)
+		}
+		items = append(items,
+			paletteItem{paneIndex: i, key: focusKey, label: "Focus pane", run: func() {}},
+			paletteItem{paneIndex: i, label: "Run a command", run: func() {
+				v.commandOutputModal.callerPaneIndex = i
+				v.commandOutputModal.inputField = v.openCommandOutputModal()
+				v.disablePanesMouse()
+			}},
+			paletteItem{paneIndex: i, label: "Start pane", run: func() { v.startPane(i) }},
+			paletteItem{paneIndex: i, label: "Stop pane", run: func() { v.stopPane(i) }},
+			paletteItem{paneIndex: i, label: "Toggle pane size", run: v.togglePaneSize},
+			paletteItem{paneIndex: i, label: "Clear pane", run: func() { v.clearPane(i) }},
+			paletteItem{paneIndex: i, label: "Save pane output", run: func() { v.savePaneOutput(i) }},
+			paletteItem{paneIndex: i, label: "Show pane history", run: func() { v.openPaneHistoryModal(i) }},
+			paletteItem{paneIndex: i, label: "Open Git panel", run: func() { v.openGitPanelModal(i) }},
+		)
+	}
+	return items
+}
+
+// paneCommandPaletteItems returns the configured commands of the pane at index, sorted by key.
+func (v *View) paneCommandPaletteItems(index int) []paletteItem {
+	var items []paletteItem
+	for _, command := range v.paneCommands(index) {
+		configCommand := command.command
+		items = append(items, paletteItem{
+			paneIndex: index,
+			key:       command.key,
+			label:     cmp.Or(configCommand.Description, command.commandText()),
+			run:       func() { v.prepareConfigCommand(index, configCommand) },
+		})
+	}
+	return items
+}
+
+// matchPaletteItems returns the items matching query, best first. Items with the same score keep
+// their order, which lists the caller pane first.
+func (v *View) matchPaletteItems(items []paletteItem, query string) []paletteItem {
+	type match struct {
+		item  paletteItem
+		score int
+	}
+	var matches []match
+	for _, item := range items {
+		if score, ok := fuzzy.Match(query, item.searchText(v.panes[item.paneIndex].config.Name)); ok {
+			matches = append(matches, match{item: item, score: score})
+		}
+	}
+	slices.SortStableFunc(matches, func(a, b match) int { return cmp.Compare(b.score, a.score) })
+	result := make([]paletteItem, len(matches))
+	for i, m := range matches {
+		result[i] = m.item
+	}
+	return result
+}
+
+// openCommandPaletteModal lists the commands and actions of every pane, filtered by a fuzzy search
+// as you type. Up and Down select an entry, Enter runs it and Esc closes the palette.
+func (v *View) openCommandPaletteModal(index int) {
+	c := v.commandPaletteModal
+	c.callerPaneIndex = index
+	c.items = v.paletteItems(index)
+
+	list := tview.NewList().ShowSecondaryText(false).SetHighlightFullLine(true)
+	list.SetBorder(true)
+	inputField := tview.NewInputField().
+		SetLabel("> ").
+		SetFieldWidth(0).
+		SetFieldBackgroundColor(tcell.ColorBlack)
+	inputField.SetBorder(true).SetTitle("Command Palette")
+	inputField.SetChangedFunc(func(text string) {
+		v.filterCommandPalette(text)
+	})
+	inputField.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEsc:
+			v.removeCommandPaletteModal()
+			v.tviewApp.SetFocus(v.panes[index].textView)
+			return nil
+		case tcell.KeyUp:
+			if current := list.GetCurrentItem(); current > 0 {
+				list.SetCurrentItem(current - 1)
+			}
+			return nil
+		case tcell.KeyDown:
+			list.SetCurrentItem(list.GetCurrentItem() + 1)
+			return nil
+		case tcell.KeyEnter:
+			v.runSelectedPaletteItem()
+			return nil
+		}
+		return event
+	})
+
+	layout := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(inputField, 3, 0, true).
+		AddItem(list, 0, 1, false)
+	modal := func(p tview.Primitive) *tview.Grid {
+		g := tview.NewGrid()
+		g.SetDrawFunc(func(_ tcell.Screen, x, y, width, height int) (int, int, int, int) {
+			if width > 90 {
+				g.SetColumns(0, 80, 0)
+			} else {
+				g.SetColumns(2, 0, 2)
+			}
+			return x, y, width, height
+		})
+		return g.
+			SetColumns(2, 0, 2).
+			SetRows(2, 0, 2).
+			AddItem(p, 1, 1, 1, 1, 0, 0, true)
+	}
+
+	c.inputField = inputField
+	c.list = list
+	v.filterCommandPalette("")
+	v.tviewPages.AddPage(constant.Page.CommandPaletteModalPage, modal(layout), true, true)
+	v.disablePanesMouse()
+}
+
+// filterCommandPalette lists the palette items matching query.
+func (v *View) filterCommandPalette(query string) {
+	c := v.commandPaletteModal
+	c.matches = v.matchPaletteItems(c.items, query)
+	c.list.Clear()
+	for _, item := range c.matches {
+		key := "  "
+		if item.key != "" {
+			key = fmt.Sprintf("[yellow]%s[white] ", tview.Escape(item.key))
+		}
+		c.list.AddItem(
+			fmt.Sprintf(
+				"[lightgreen]%s[white] %s%s",
+				tview.Escape(v.panes[item.paneIndex].config.Name),
+				key,
+				tview.Escape(item.label),
+			),
+			"",
+			0,
+			nil,
+		)
+	}
+	c.list.SetTitle(fmt.Sprintf("%d/%d", len(c.matches), len(c.items)))
+}
+
+// runSelectedPaletteItem closes the palette, focuses the pane of the selected item and runs it.
+func (v *View) runSelectedPaletteItem() {
+	c := v.commandPaletteModal
+	current := c.list.GetCurrentItem()
+	if current < 0 || current >= len(c.matches) {
+		return
+	}
+	item := c.matches[current]
+	v.removeCommandPaletteModal()
+	v.tviewApp.SetFocus(v.panes[item.paneIndex].textView)
+	item.run()
+}
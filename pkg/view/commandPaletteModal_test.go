@@ -0,0 +1,110 @@
+package view
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/jiyeol-lee/localdev/pkg/config"
+	"github.com/jiyeol-lee/localdev/pkg/constant"
+	"github.com/rivo/tview"
+)
+
+func newTestPaletteView(app *tview.Application) *View {
+	return &View{
+		tviewApp: app,
+		panes: []*Pane{
+			{
+				textView: tview.NewTextView(),
+				config: config.ConfigPane{Name: "api", Commands: &config.ConfigCommands{
+					LowerT: &config.ConfigCommand{Command: "make test", Description: "Run tests"},
+					UpperB: &config.ConfigCommand{Command: "make build"},
+					LowerA: &config.ConfigCommand{Steps: []string{"<stop_pane>", "<start_pane>"}},
+				}},
+			},
+			{textView: tview.NewTextView(), config: config.ConfigPane{Name: "db"}},
+		},
+		commandOutputModal:  newCommandOutputModal(),
+		commandPaletteModal: newCommandPaletteModal(),
+	}
+}
+
+func TestView_paletteItems(t *testing.T) {
+	v := newTestPaletteView(nil)
+
+	var got []string
+	for _, item := range v.paletteItems(1) {
+		got = append(got, v.panes[item.paneIndex].config.Name+"|"+item.key+"|"+item.label)
+	}
+	want := map[int]string{
+		0:  "db|2|Focus pane",
+		9:  "api|a|<stop_pane> → <start_pane>",
+		10: "api|B|make build",
+		11: "api|t|Run tests",
+		12: "api|1|Focus pane",
+	}
+	if len(got) != 21 {
+		t.Fatalf("paletteItems() = %d items %v, want 21", len(got), got)
+	}
+	for i, w := range want {
+		if got[i] != w {
+			t.Errorf("paletteItems()[%d] = %q, want %q", i, got[i], w)
+		}
+	}
+}
+
+func TestView_matchPaletteItems(t *testing.T) {
+	v := newTestPaletteView(nil)
+	items := v.paletteItems(0)
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{query: "db stop", want: "db|Stop pane"},
+		{query: "tests", want: "api|Run tests"},
+		{query: "git", want: "api|Open Git panel"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			matches := v.matchPaletteItems(items, tt.query)
+			if len(matches) == 0 {
+				t.Fatalf("matchPaletteItems(%q) found nothing", tt.query)
+			}
+			if got := v.panes[matches[0].paneIndex].config.Name + "|" + matches[0].label; got != tt.want {
+				t.Errorf("matchPaletteItems(%q)[0] = %q, want %q", tt.query, got, tt.want)
+			}
+		})
+	}
+	if matches := v.matchPaletteItems(items, "zzz"); len(matches) != 0 {
+		t.Errorf("matchPaletteItems(zzz) = %d items, want none", len(matches))
+	}
+}
+
+func TestView_commandPaletteModal_RunsSelection(t *testing.T) {
+	app := startTestTviewApplication(t)
+	v := newTestPaletteView(app)
+	db := v.panes[1]
+	grid := tview.NewFlex().AddItem(v.panes[0].textView, 0, 1, true).AddItem(db.textView, 0, 1, false)
+	v.tviewPages = tview.NewPages().AddPage(constant.Page.MainPage, grid, true, true)
+	// The grid of the modal passes keys on only once it has been drawn.
+	app.QueueUpdateDraw(func() {
+		app.SetRoot(v.tviewPages, true)
+		_, _ = db.textView.Write([]byte("old output\n"))
+		db.history.append("old output")
+		v.openCommandPaletteModal(0)
+	})
+
+	for _, r := range "db clear" {
+		app.QueueEvent(tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone))
+	}
+	waitForUI(t, app, func() bool {
+		return v.commandPaletteModal.inputField.GetText() == "db clear"
+	})
+	app.QueueEvent(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone))
+	waitForUI(t, app, func() bool {
+		return !v.checkIsCommandPaletteModalOpen() && db.textView.HasFocus()
+	})
+	if got := db.history.text(); got != "" {
+		t.Errorf("db history = %q, want it cleared", got)
+	}
+}
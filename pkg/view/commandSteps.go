@@ -0,0 +1,77 @@
+package view
+
+import (
+	"time"
+
+	"github.com/jiyeol-lee/localdev/internal/logger"
+	"github.com/jiyeol-lee/localdev/pkg/constant"
+	"github.com/rivo/tview"
+)
+
+// runCommandSteps runs the steps of a command of the pane at index in order and stops at the first
+// one that fails. Shell steps run in the pane dir with the pane env and write their output into the
+// pane; <start_pane> and <stop_pane> wait until the pane has started or stopped.
+func (v *View) runCommandSteps(index int, steps []string) {
+	p := v.panes[index]
+	if !p.runningSteps.CompareAndSwap(false, true) {
+		v.queueUpdate(func() {
+			v.writePaneMessage(p, "[yellow]Another command is still running its steps[-]\n")
+		})
+		return
+	}
+	defer p.runningSteps.Store(false)
+
+	for i, step := range steps {
+		v.queueUpdate(func() {
+			v.writePaneMessage(
+				p,
+				"[gray]━━━ Step %d/%d: %s ━━━[-]\n",
+				i+1,
+				len(steps),
+				tview.Escape(step),
+			)
+		})
+		if v.runCommandStep(index, step) {
+			continue
+		}
+
+		logger.Errorf("step %d/%d %q of pane %s failed", i+1, len(steps), step, p.config.Name)
+		skipped := len(steps) - i - 1
+		v.queueUpdate(func() {
+			v.writePaneMessage(
+				p,
+				"[red]━━━ Step %d/%d failed; skipped %d remaining step(s) ━━━[-]\n",
+				i+1,
+				len(steps),
+				skipped,
+			)
+		})
+		return
+	}
+	v.queueUpdate(func() {
+		v.writePaneMessage(
+			p,
+			"[gray]━━━ All %d steps finished at %s ━━━[-]\n",
+			len(steps),
+			time.Now().Format("15:04:05"),
+		)
+	})
+}
+
+// runCommandStep runs one step of a command of the pane at index and reports whether it succeeded.
+func (v *View) runCommandStep(index int, step string) bool {
+	switch step {
+	case constant.ReservedCommand.StartPane:
+		return v.startPaneSync(index)
+	case constant.ReservedCommand.StopPane:
+		return v.stopPaneSync(index)
+	case constant.ReservedCommand.ClearPane:
+		v.queueUpdate(func() { v.clearPane(index) })
+		return true
+	case constant.ReservedCommand.SavePaneOutput:
+		v.queueUpdate(func() { v.savePaneOutput(index) })
+		return true
+	default:
+		return v.runPaneCommandToTextView(v.panes[index], step) == 0
+	}
+}
@@ -0,0 +1,125 @@
+package view
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/jiyeol-lee/localdev/pkg/config"
+	"github.com/jiyeol-lee/localdev/pkg/constant"
+	"github.com/rivo/tview"
+	"golang.org/x/sys/unix"
+)
+
+func TestView_runCommandSteps(t *testing.T) {
+	tests := []struct {
+		name string
+		// steps run with a pane whose start command prints "started" and whose stop command
+		// prints "stopped".
+		steps []string
+		// wantInOrder must appear in the pane history in this order.
+		wantInOrder []string
+		notWant     string
+	}{
+		{
+			name:  "stops at the first failing step",
+			steps: []string{"echo one", "echo 'reset failed'; exit 4", "echo three"},
+			wantInOrder: []string{
+				"Step 1/3: echo one",
+				"one",
+				"reset failed",
+				"Step 2/3 failed; skipped 1 remaining step(s)",
+			},
+			notWant: "three",
+		},
+		{
+			name:  "runs reserved steps in order",
+			steps: []string{"<stop_pane>", "echo reset", "<start_pane>"},
+			wantInOrder: []string{
+				"stopped",
+				"Step 2/3: echo reset",
+				"reset",
+				"Step 3/3: <start_pane>",
+				"All 3 steps finished",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := startTestTviewApplication(t)
+			p := &Pane{
+				textView: tview.NewTextView(),
+				config: config.ConfigPane{
+					Name:  "db",
+					Dir:   t.TempDir(),
+					Start: "echo started; exec sleep 30",
+					Stop:  "echo stopped",
+				},
+			}
+			t.Cleanup(func() {
+				p.mu.Lock()
+				defer p.mu.Unlock()
+				if p.cmd != nil && p.cmd.Process != nil {
+					_ = unix.Kill(-p.cmd.Process.Pid, unix.SIGKILL)
+				}
+			})
+			v := &View{tviewApp: app, panes: []*Pane{p}}
+
+			v.runCommandSteps(0, tt.steps)
+			last := tt.wantInOrder[len(tt.wantInOrder)-1]
+			waitForUI(t, app, func() bool { return strings.Contains(p.history.text(), last) })
+
+			history := p.history.text()
+			pos := 0
+			for _, want := range tt.wantInOrder {
+				i := strings.Index(history[pos:], want)
+				if i < 0 {
+					t.Fatalf("history = %q, want %q after position %d", history, want, pos)
+				}
+				pos += i + len(want)
+			}
+			if tt.notWant != "" && strings.Contains(history, tt.notWant) {
+				t.Errorf("history = %q, want no %q", history, tt.notWant)
+			}
+			if p.runningSteps.Load() {
+				t.Error("runningSteps is still set after the steps finished")
+			}
+		})
+	}
+}
+
+func TestView_openConfirmModal(t *testing.T) {
+	tests := []struct {
+		name          string
+		key           rune
+		wantConfirmed bool
+	}{
+		{name: "yes", key: 'y', wantConfirmed: true},
+		{name: "no", key: 'n', wantConfirmed: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := startTestTviewApplication(t)
+			p := &Pane{textView: tview.NewTextView(), config: config.ConfigPane{Name: "db"}}
+			pages := tview.NewPages().AddPage(constant.Page.MainPage, p.textView, true, true)
+			v := &View{tviewApp: app, tviewPages: pages, panes: []*Pane{p}}
+
+			confirmed := false
+			app.QueueUpdate(func() {
+				app.SetRoot(pages, true)
+				v.openConfirmModal(0, "Drop the database?", func() { confirmed = true })
+			})
+			app.QueueEvent(tcell.NewEventKey(tcell.KeyRune, tt.key, tcell.ModNone))
+			waitForUI(t, app, func() bool { return !v.checkIsConfirmModalOpen() })
+
+			var focused bool
+			app.QueueUpdate(func() { focused = p.textView.HasFocus() })
+			if confirmed != tt.wantConfirmed {
+				t.Errorf("confirmed = %v, want %v", confirmed, tt.wantConfirmed)
+			}
+			if !focused {
+				t.Error("pane is not focused after the modal closed")
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package view
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"github.com/jiyeol-lee/localdev/pkg/constant"
+	"github.com/rivo/tview"
+)
+
+const (
+	confirmModalYes = "Yes"
+	confirmModalNo  = "No"
+)
+
+func (v *View) checkIsConfirmModalOpen() bool {
+	return v.tviewPages.HasPage(constant.Page.ConfirmModalPage)
+}
+
+// openConfirmModal asks question in a yes/no modal over the pane at index and calls onConfirm once
+// the user answers yes. "No" is focused first; y and n answer directly and Esc cancels.
+func (v *View) openConfirmModal(index int, question string, onConfirm func()) {
+	if v.checkIsConfirmModalOpen() {
+		return
+	}
+	answer := func(confirmed bool) {
+		v.tviewPages.RemovePage(constant.Page.ConfirmModalPage)
+		v.enablePanesMouse()
+		v.tviewApp.SetFocus(v.panes[index].textView)
+		if confirmed {
+			onConfirm()
+		}
+	}
+
+	modal := tview.NewModal().
+		SetText(question).
+		AddButtons([]string{confirmModalYes, confirmModalNo}).
+		SetFocus(1).
+		SetDoneFunc(func(_ int, buttonLabel string) {
+			answer(buttonLabel == confirmModalYes)
+		})
+	modal.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Rune() {
+		case 'y', 'Y':
+			answer(true)
+			return nil
+		case 'n', 'N':
+			answer(false)
+			return nil
+		}
+		return event
+	})
+
+	v.tviewPages.AddPage(constant.Page.ConfirmModalPage, modal, true, true)
+	v.disablePanesMouse()
+}
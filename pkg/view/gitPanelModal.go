@@ -0,0 +1,303 @@
+package view
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/jiyeol-lee/localdev/internal/logger"
+	"github.com/jiyeol-lee/localdev/pkg/command"
+	"github.com/jiyeol-lee/localdev/pkg/constant"
+	"github.com/rivo/tview"
+)
+
+// gitPanelCommitLimit is the number of recent commits listed in the git panel.
+const gitPanelCommitLimit = 20
+
+// gitPanelAction is a git command bound to a key in the git panel.
+type gitPanelAction struct {
+	key   rune
+	label string
+	args  []string
+}
+
+var gitPanelActions = []gitPanelAction{
+	{key: 'p', label: "pull", args: []string{"pull"}},
+	{key: 'f', label: "fetch", args: []string{"fetch", "--all", "--prune"}},
+	{key: 's', label: "stash", args: []string{"stash", "push", "--include-untracked"}},
+	{key: 'u', label: "unstash", args: []string{"stash", "pop"}},
+}
+
+type gitPanelModal struct {
+	callerPaneIndex int
+	changesList     *tview.List
+	commitsList     *tview.List
+	branchesList    *tview.List
+	outputView      *tview.TextView
+	// running is only accessed on the UI goroutine and outlives reset so a closed panel
+	// cannot start a second command while the first is still running.
+	running bool
+}
+
+func newGitPanelModal() *gitPanelModal {
+	return &gitPanelModal{
+		callerPaneIndex: -1,
+	}
+}
+
+func (g *gitPanelModal) reset() {
+	g.callerPaneIndex = -1
+	g.changesList = nil
+	g.commitsList = nil
+	g.branchesList = nil
+	g.outputView = nil
+}
+
+func (v *View) checkIsGitPanelModalOpen() bool {
+	return v.tviewPages.HasPage(constant.Page.GitPanelModalPage)
+}
+
+func (v *View) removeGitPanelModal() {
+	v.tviewPages.RemovePage(constant.Page.GitPanelModalPage)
+	v.gitPanelModal.reset()
+	v.enablePanesMouse()
+}
+
+// openGitPanelModal shows the changed files, recent commits and local branches of the repository
+// of the pane at index, with actions that run git in the pane directory.
+func (v *View) openGitPanelModal(index int) {
+	pane := v.panes[index]
+	dir := pane.config.Dir
+
+	newList := func(title string) *tview.List {
+		l := tview.NewList().ShowSecondaryText(false).SetHighlightFullLine(true)
+		l.SetBorder(true).SetTitle(title)
+		return l
+	}
+	changesList := newList("Changes")
+	commitsList := newList("Recent Commits")
+	branchesList := newList("Branches")
+	outputView := tview.NewTextView().SetDynamicColors(true).SetScrollable(true)
+	outputView.SetBorder(true).SetTitle("Output")
+
+	branchesList.SetSelectedFunc(func(_ int, _ string, branch string, _ rune) {
+		if branch != "" {
+			v.runGitPanelCommand(pane, "checkout", branch)
+		}
+	})
+
+	var helpParts []string
+	helpParts = append(helpParts, "[green]Enter[-] checkout branch")
+	for _, action := range gitPanelActions {
+		helpParts = append(helpParts, fmt.Sprintf("[green]%c[-] %s", action.key, action.label))
+	}
+	helpParts = append(
+		helpParts,
+		"[green]r[-] refresh",
+		"[green]Tab[-] next list",
+		"[green]Esc[-] close",
+	)
+	helpView := tview.NewTextView().
+		SetDynamicColors(true).
+		SetText(" " + strings.Join(helpParts, "  "))
+
+	lists := tview.NewFlex().
+		AddItem(branchesList, 0, 1, true).
+		AddItem(changesList, 0, 1, false).
+		AddItem(commitsList, 0, 2, false)
+	root := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(lists, 0, 2, true).
+		AddItem(outputView, 0, 1, false).
+		AddItem(helpView, 1, 0, false)
+	root.SetBorder(true).SetTitle(fmt.Sprintf("%s - Git", pane.config.Name))
+
+	focusOrder := []tview.Primitive{branchesList, changesList, commitsList, outputView}
+	focusIndex := 0
+	root.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEsc:
+			callerPaneTextView := v.panes[v.gitPanelModal.callerPaneIndex].textView
+			v.removeGitPanelModal()
+			v.tviewApp.SetFocus(callerPaneTextView)
+			return nil
+		case tcell.KeyTab:
+			focusIndex = (focusIndex + 1) % len(focusOrder)
+			v.tviewApp.SetFocus(focusOrder[focusIndex])
+			return nil
+		case tcell.KeyBacktab:
+			focusIndex = (focusIndex + len(focusOrder) - 1) % len(focusOrder)
+			v.tviewApp.SetFocus(focusOrder[focusIndex])
+			return nil
+		case tcell.KeyRune:
+			if event.Rune() == 'r' {
+				v.refreshGitPanel(dir)
+				return nil
+			}
+			for _, action := range gitPanelActions {
+				if event.Rune() == action.key {
+					v.runGitPanelCommand(pane, action.args...)
+					return nil
+				}
+			}
+		}
+		return event
+	})
+
+	modal := func(p tview.Primitive) *tview.Grid {
+		return tview.NewGrid().
+			SetColumns(2, 0, 2).
+			SetRows(1, 0, 1).
+			AddItem(p, 1, 1, 1, 1, 0, 0, true)
+	}
+
+	v.gitPanelModal.callerPaneIndex = index
+	v.gitPanelModal.changesList = changesList
+	v.gitPanelModal.commitsList = commitsList
+	v.gitPanelModal.branchesList = branchesList
+	v.gitPanelModal.outputView = outputView
+	v.tviewPages.AddPage(constant.Page.GitPanelModalPage, modal(root), true, true)
+	v.disablePanesMouse()
+	v.refreshGitPanel(dir)
+}
+
+// refreshGitPanel reloads the lists of the open git panel in the background.
+func (v *View) refreshGitPanel(dir string) {
+	changesList := v.gitPanelModal.changesList
+	commitsList := v.gitPanelModal.commitsList
+	branchesList := v.gitPanelModal.branchesList
+	if changesList == nil || commitsList == nil || branchesList == nil {
+		return
+	}
+
+	go func() {
+		changes, changesErr := command.GetChangedFiles(dir)
+		commits, commitsErr := command.GetRecentCommits(dir, gitPanelCommitLimit)
+		branches, branchesErr := command.GetLocalBranches(dir)
+		currentBranch, _ := command.GetCurrentBranch(dir)
+
+		v.queueUpdateDraw(func() {
+			changesList.Clear()
+			switch {
+			case changesErr != nil:
+				changesList.AddItem(fmt.Sprintf("[red]%s[-]", tview.Escape(changesErr.Error())), "", 0, nil)
+			case len(changes) == 0:
+				changesList.AddItem("[gray]No changes[-]", "", 0, nil)
+			}
+			for _, change := range changes {
+				changesList.AddItem(
+					fmt.Sprintf("[yellow]%s[-] %s", tview.Escape(change.Status), tview.Escape(change.Path)),
+					change.Path,
+					0,
+					nil,
+				)
+			}
+
+			commitsList.Clear()
+			switch {
+			case commitsErr != nil:
+				commitsList.AddItem(fmt.Sprintf("[red]%s[-]", tview.Escape(commitsErr.Error())), "", 0, nil)
+			case len(commits) == 0:
+				commitsList.AddItem("[gray]No commits[-]", "", 0, nil)
+			}
+			for _, commit := range commits {
+				commitsList.AddItem(
+					fmt.Sprintf(
+						"[yellow]%s[-] %s [gray](%s, %s)[-]",
+						commit.SHA,
+						tview.Escape(commit.Subject),
+						tview.Escape(commit.Author),
+						commit.RelativeDate,
+					),
+					commit.SHA,
+					0,
+					nil,
+				)
+			}
+
+			selectedBranch := branchesList.GetCurrentItem()
+			branchesList.Clear()
+			if branchesErr != nil {
+				branchesList.AddItem(fmt.Sprintf("[red]%s[-]", tview.Escape(branchesErr.Error())), "", 0, nil)
+			}
+			for _, branch := range branches {
+				text := "  " + tview.Escape(branch)
+				if branch == currentBranch {
+					text = "[green]* " + tview.Escape(branch) + "[-]"
+				}
+				branchesList.AddItem(text, branch, 0, nil)
+			}
+			if selectedBranch < branchesList.GetItemCount() {
+				branchesList.SetCurrentItem(selectedBranch)
+			}
+		})
+	}()
+}
+
+// runGitPanelCommand runs git with args in the dir and environment of pane, streaming its output
+// into the git panel and refreshing the panel and the pane titles when it completes.
+// It must be called on the UI goroutine.
+func (v *View) runGitPanelCommand(pane *Pane, args ...string) {
+	dir := pane.config.Dir
+	outputView := v.gitPanelModal.outputView
+	if outputView == nil {
+		return
+	}
+	if v.gitPanelModal.running {
+		_, _ = fmt.Fprintf(outputView, "[yellow]Another git command is still running[-]\n")
+		return
+	}
+	v.gitPanelModal.running = true
+	commandLine := "git " + strings.Join(args, " ")
+	_, _ = fmt.Fprintf(outputView, "[green]$ %s[-]\n", tview.Escape(commandLine))
+	outputView.ScrollToEnd()
+
+	go func() {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = v.paneCommandEnv(pane)
+		// Fail instead of waiting for credentials that cannot be entered from the panel.
+		cmd.Env = append(cmd.Env, "GIT_TERMINAL_PROMPT=0")
+		reader, writer := io.Pipe()
+		cmd.Stdout = writer
+		cmd.Stderr = writer
+
+		scanDone := make(chan struct{})
+		go func() {
+			defer close(scanDone)
+			scanner := bufio.NewScanner(reader)
+			for scanner.Scan() {
+				line := tview.Escape(v.masker.Mask(scanner.Text()))
+				v.queueUpdateDraw(func() {
+					_, _ = fmt.Fprintln(outputView, line)
+				})
+			}
+		}()
+
+		err := cmd.Run()
+		_ = writer.Close()
+		<-scanDone
+
+		if err != nil {
+			logger.Warnf("git panel command %q failed in %s: %v", commandLine, dir, err)
+		}
+		v.queueUpdateDraw(func() {
+			v.gitPanelModal.running = false
+			if err != nil {
+				_, _ = fmt.Fprintf(outputView, "[red]%s failed: %v[-]\n\n", tview.Escape(commandLine), err)
+			} else {
+				_, _ = fmt.Fprintf(outputView, "[gray]%s finished[-]\n\n", tview.Escape(commandLine))
+			}
+			outputView.ScrollToEnd()
+			if v.gitPanelModal.outputView == outputView {
+				v.refreshGitPanel(dir)
+			}
+		})
+		if v.gitStatusPoller != nil {
+			v.gitStatusPoller.Refresh(dir)
+		}
+	}()
+}
@@ -0,0 +1,91 @@
+package view
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jiyeol-lee/localdev/pkg/config"
+	"github.com/jiyeol-lee/localdev/pkg/internal/gittest"
+	"github.com/rivo/tview"
+)
+
+// waitForUI polls check on the UI goroutine until it returns true or the timeout expires.
+func waitForUI(t *testing.T, app *tview.Application, check func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		result := make(chan bool, 1)
+		app.QueueUpdate(func() { result <- check() })
+		if <-result {
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatal("timed out waiting for the UI condition")
+}
+
+func TestView_gitPanelModal_ListsBranchesAndRunsCommands(t *testing.T) {
+	repo := gittest.NewRepo(t)
+	gittest.Run(t, repo, "branch", "feature")
+
+	app := startTestTviewApplication(t)
+	v := &View{
+		tviewApp:      app,
+		tviewPages:    tview.NewPages(),
+		gitPanelModal: newGitPanelModal(),
+		panes: []*Pane{{
+			textView: tview.NewTextView(),
+			config:   config.ConfigPane{Name: "api", Dir: repo},
+			env:      []string{"GIT_AUTHOR_NAME=pane-author", "GIT_AUTHOR_EMAIL=pane@example.com"},
+		}},
+	}
+
+	app.QueueUpdate(func() { v.openGitPanelModal(0) })
+	waitForUI(t, app, func() bool {
+		list := v.gitPanelModal.branchesList
+		if list == nil || list.GetItemCount() != 2 {
+			return false
+		}
+		for i := range list.GetItemCount() {
+			main, branch := list.GetItemText(i)
+			if branch == "main" && strings.HasPrefix(main, "[green]* ") {
+				return true
+			}
+		}
+		return false
+	})
+
+	app.QueueUpdate(func() { v.runGitPanelCommand(v.panes[0], "checkout", "feature") })
+	waitForUI(t, app, func() bool {
+		return strings.Contains(
+			v.gitPanelModal.outputView.GetText(true),
+			"git checkout feature finished",
+		)
+	})
+	waitForUI(t, app, func() bool {
+		for i := range v.gitPanelModal.branchesList.GetItemCount() {
+			main, branch := v.gitPanelModal.branchesList.GetItemText(i)
+			if branch == "feature" && strings.HasPrefix(main, "[green]* ") {
+				return true
+			}
+		}
+		return false
+	})
+
+	app.QueueUpdate(func() { v.runGitPanelCommand(v.panes[0], "checkout", "missing-branch") })
+	waitForUI(t, app, func() bool {
+		return strings.Contains(
+			v.gitPanelModal.outputView.GetText(true),
+			"git checkout missing-branch failed",
+		)
+	})
+
+	app.QueueUpdate(func() { v.runGitPanelCommand(v.panes[0], "var", "GIT_AUTHOR_IDENT") })
+	waitForUI(t, app, func() bool {
+		return strings.Contains(v.gitPanelModal.outputView.GetText(true), "git var GIT_AUTHOR_IDENT finished")
+	})
+	if got := v.gitPanelModal.outputView.GetText(true); !strings.Contains(got, "pane-author") {
+		t.Fatalf("output = %q, want the pane env to be used", got)
+	}
+}
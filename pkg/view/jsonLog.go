@@ -0,0 +1,139 @@
+package view
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/rivo/tview"
+)
+
+var (
+	jsonLogTimeKeys    = []string{"time", "ts", "timestamp", "@timestamp"}
+	jsonLogLevelKeys   = []string{"level", "lvl", "severity", "@level"}
+	jsonLogMessageKeys = []string{"msg", "message", "@message"}
+)
+
+// formatJSONLogLine renders a structured JSON log line as "time level msg key=value".
+// Only the keys in fields are shown after the message; all remaining keys are shown when fields is empty.
+// It reports false when the line is not a JSON object so callers can fall back to the raw text.
+func formatJSONLogLine(line string, fields []string) (string, bool) {
+	trimmed := strings.TrimSpace(line)
+	if !strings.HasPrefix(trimmed, "{") {
+		return "", false
+	}
+	decoder := json.NewDecoder(strings.NewReader(trimmed))
+	decoder.UseNumber()
+	var entry map[string]any
+	if err := decoder.Decode(&entry); err != nil || decoder.More() {
+		return "", false
+	}
+
+	var parts []string
+	used := make(map[string]bool)
+	takeFirst := func(keys []string) (any, bool) {
+		for _, key := range keys {
+			if value, ok := entry[key]; ok {
+				used[key] = true
+				return value, true
+			}
+		}
+		return nil, false
+	}
+
+	if value, ok := takeFirst(jsonLogTimeKeys); ok {
+		parts = append(parts, "[gray]"+tview.Escape(formatJSONLogTime(value))+"[-]")
+	}
+	if value, ok := takeFirst(jsonLogLevelKeys); ok {
+		level := strings.ToUpper(jsonLogValueString(value))
+		parts = append(parts, jsonLogLevelColor(level)+tview.Escape(level)+"[-]")
+	}
+	if value, ok := takeFirst(jsonLogMessageKeys); ok {
+		parts = append(parts, tview.Escape(jsonLogValueString(value)))
+	}
+
+	keys := fields
+	if len(keys) == 0 {
+		for key := range entry {
+			if !used[key] {
+				keys = append(keys, key)
+			}
+		}
+		slices.Sort(keys)
+	}
+	for _, key := range keys {
+		value, ok := entry[key]
+		if !ok {
+			continue
+		}
+		s := jsonLogValueString(value)
+		if strings.ContainsAny(s, " \t") {
+			s = fmt.Sprintf("%q", s)
+		}
+		parts = append(parts, fmt.Sprintf("[gray]%s=[-]%s", tview.Escape(key), tview.Escape(s)))
+	}
+
+	return strings.Join(parts, " "), true
+}
+
+// formatJSONLogTime shortens RFC 3339 timestamps and Unix epochs to a local wall-clock time.
+func formatJSONLogTime(value any) string {
+	switch v := value.(type) {
+	case string:
+		if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+			return t.Local().Format("15:04:05.000")
+		}
+		return v
+	case json.Number:
+		if f, err := v.Float64(); err == nil {
+			// Treat large values as milliseconds since the epoch.
+			if f > 1e12 {
+				return time.UnixMilli(int64(f)).Local().Format("15:04:05.000")
+			}
+			return time.UnixMilli(int64(f * 1000)).Local().Format("15:04:05.000")
+		}
+		return v.String()
+	default:
+		return jsonLogValueString(v)
+	}
+}
+
+// jsonLogLevelColor returns the color tag used for the given upper-case level.
+func jsonLogLevelColor(level string) string {
+	switch {
+	case strings.HasPrefix(level, "ERR"), strings.HasPrefix(level, "FATAL"),
+		strings.HasPrefix(level, "PANIC"), strings.HasPrefix(level, "CRIT"):
+		return "[red]"
+	case strings.HasPrefix(level, "WARN"):
+		return "[yellow]"
+	case strings.HasPrefix(level, "INFO"):
+		return "[green]"
+	case strings.HasPrefix(level, "DEBUG"), strings.HasPrefix(level, "TRACE"):
+		return "[gray]"
+	default:
+		return "[white]"
+	}
+}
+
+// jsonLogValueString renders a decoded JSON value, keeping nested values compact.
+func jsonLogValueString(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case nil:
+		return "null"
+	default:
+		var buf bytes.Buffer
+		encoder := json.NewEncoder(&buf)
+		encoder.SetEscapeHTML(false)
+		if err := encoder.Encode(v); err != nil {
+			return fmt.Sprint(v)
+		}
+		return strings.TrimSpace(buf.String())
+	}
+}
@@ -0,0 +1,82 @@
+package view
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_formatJSONLogLine(t *testing.T) {
+	tests := []struct {
+		name     string
+		line     string
+		fields   []string
+		want     string
+		wantOK   bool
+		contains []string
+	}{
+		{
+			name:   "non-JSON line falls back",
+			line:   "server listening on :8080",
+			wantOK: false,
+		},
+		{
+			name:   "invalid JSON falls back",
+			line:   `{"level": "info"`,
+			wantOK: false,
+		},
+		{
+			name:   "level and message with remaining keys sorted",
+			line:   `{"level":"error","msg":"request failed","status":500,"path":"/api"}`,
+			want:   "[red]ERROR[-] request failed [gray]path=[-]/api [gray]status=[-]500",
+			wantOK: true,
+		},
+		{
+			name:   "only selected fields are shown",
+			line:   `{"level":"warn","message":"slow query","duration":"2s","query":"select 1"}`,
+			fields: []string{"duration", "missing"},
+			want:   "[yellow]WARN[-] slow query [gray]duration=[-]2s",
+			wantOK: true,
+		},
+		{
+			name:   "values with spaces are quoted and nested values stay compact",
+			line:   `{"msg":"hi","user":"john doe","meta":{"a":1}}`,
+			want:   `hi [gray]meta=[-]{"a":1} [gray]user=[-]"john doe"`,
+			wantOK: true,
+		},
+		{
+			name:     "color tags in values are escaped",
+			line:     `{"level":"info","msg":"[red]boom"}`,
+			wantOK:   true,
+			contains: []string{"[green]INFO[-]", "[red[]boom"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := formatJSONLogLine(tt.line, tt.fields)
+			if ok != tt.wantOK {
+				t.Fatalf("formatJSONLogLine() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if tt.want != "" && got != tt.want {
+				t.Errorf("formatJSONLogLine() = %q, want %q", got, tt.want)
+			}
+			for _, c := range tt.contains {
+				if !strings.Contains(got, c) {
+					t.Errorf("formatJSONLogLine() = %q, want it to contain %q", got, c)
+				}
+			}
+		})
+	}
+}
+
+func Test_formatJSONLogLine_Time(t *testing.T) {
+	got, ok := formatJSONLogLine(`{"time":"2024-01-02T03:04:05.678Z","msg":"boot"}`, nil)
+	if !ok {
+		t.Fatal("expected line to be formatted")
+	}
+	if !strings.HasPrefix(got, "[gray]") || !strings.HasSuffix(got, "[-] boot") {
+		t.Errorf("formatJSONLogLine() = %q, want gray time followed by message", got)
+	}
+	if strings.Contains(got, "2024-01-02") {
+		t.Errorf("formatJSONLogLine() = %q, want time shortened to the wall clock", got)
+	}
+}
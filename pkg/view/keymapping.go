@@ -1,8 +1,8 @@
 package view
 
 import (
+	"cmp"
 	"fmt"
-	"os"
 	"os/exec"
 	"syscall"
 
@@ -10,8 +10,10 @@ import (
 	"github.com/jiyeol-lee/localdev/internal/logger"
 	"github.com/jiyeol-lee/localdev/pkg/config"
 	"github.com/jiyeol-lee/localdev/pkg/constant"
+	"github.com/jiyeol-lee/localdev/pkg/internal/placeholder"
 	"github.com/jiyeol-lee/localdev/pkg/internal/shell"
 	"github.com/jiyeol-lee/localdev/pkg/util"
+	"github.com/rivo/tview"
 	"golang.org/x/sys/unix"
 )
 
@@ -106,90 +108,170 @@ func (v *View) keyMapping(event *tcell.EventKey) *tcell.EventKey {
 			v.tviewApp.SetFocus(v.panes[action].textView)
 		}
 
+		if event.Key() == tcell.KeyCtrlP {
+			if !v.checkIsCommandPaletteModalOpen() {
+				v.openCommandPaletteModal(focusedViewIndex)
+			}
+			return nil
+		}
+
 		// open command help modal when '?' is pressed
 		if event.Rune() == 63 {
 			if !v.checkIsCommandHelpModalOpen() && !v.checkIsCommandOutputModalOpen() {
-				v.commandHelpModal.callerPaneIndex = focusedViewIndex
-				v.commandHelpModal.textView = v.openCommandHelpModal()
-				v.setCommandHelpModalBodyText()
-				v.disablePanesMouse()
+				v.openCommandHelpModal(focusedViewIndex)
 			}
 			return event
 		}
 
 		configPane := v.panes[focusedViewIndex].config
 		if configCommand, err := v.keyToCommand(event.Rune(), configPane); err == nil &&
-			configCommand.Command != "" {
-			if configCommand.Command == constant.ReservedCommand.TogglePaneSize {
-				v.togglePaneSize()
-				return event
-			}
-			if configCommand.Command == constant.ReservedCommand.StartPane {
-				v.startPane(focusedViewIndex)
-				return event
-			}
-			if configCommand.Command == constant.ReservedCommand.StopPane {
-				v.stopPane(focusedViewIndex)
-				return event
-			}
-			if configCommand.Silent {
-				pane := v.panes[focusedViewIndex]
-				sh := shell.Current()
-				cmd := exec.Command(sh, "-c", configCommand.Command)
-				cmd.Env = append(os.Environ(), v.envVars...)
-				cmd.Dir = configPane.Dir
-				err := cmd.Start()
-				if err != nil {
-					logger.Errorf(
-						"silent command failed to start for pane %s: %v",
-						configPane.Name,
-						err,
-					)
-					_, _ = v.panes[focusedViewIndex].textView.Write(
-						fmt.Appendf(nil, "[red]Command execution failed: %s[white]\n",
-							err,
-						),
-					)
-				} else {
-					_, _ = v.panes[focusedViewIndex].textView.Write(
-						fmt.Appendf(nil, "[green]Command started successfully: %s[white]\n",
-							configCommand.Command,
-						),
-					)
-					go func() {
-						if err := cmd.Wait(); err != nil {
-							if exitErr, ok := err.(*exec.ExitError); ok {
-								if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() && status.Signal() == unix.SIGKILL {
-									return
-								}
-							}
-							logger.Errorf("silent command execution failed for pane %s: %v", configPane.Name, err)
-							v.tviewApp.QueueUpdate(func() {
-								_, _ = pane.textView.Write(
-									fmt.Appendf(nil, "[red]Command execution failed: %s[white]\n", err),
-								)
-							})
-						}
-					}()
-				}
-				return event
-			}
-			if !v.checkIsCommandHelpModalOpen() && !v.checkIsCommandOutputModalOpen() {
-				v.commandOutputModal.callerPaneIndex = focusedViewIndex
-				v.commandOutputModal.appendCommandHistory(configCommand.Command)
-				if configCommand.AutoExecute {
-					v.runCustomUserCommand(
-						v.panes[v.commandOutputModal.callerPaneIndex].config.Dir,
-						configCommand.Command,
-					)
-				} else {
-					v.commandOutputModal.inputField = v.openCommandOutputModal()
-					v.commandOutputModal.inputField.SetText(configCommand.Command)
-				}
-				v.disablePanesMouse()
+			(configCommand.Command != "" || len(configCommand.Steps) > 0) {
+			if v.prepareConfigCommand(focusedViewIndex, configCommand) {
+				return nil
 			}
 		}
 	}
 
 	return event
 }
+
+// prepareConfigCommand asks for the placeholder values and the confirmation of a key-bound command
+// of the pane at index before executing it. It reports whether it opened a modal to ask.
+func (v *View) prepareConfigCommand(index int, configCommand *config.ConfigCommand) bool {
+	fields, err := placeholder.Parse(append([]string{configCommand.Command}, configCommand.Steps...)...)
+	if err != nil {
+		logger.Errorf("error parsing command of pane %s: %v", v.panes[index].config.Name, err)
+		v.writePaneMessage(v.panes[index], "[red]Invalid command: %s[-]\n", tview.Escape(err.Error()))
+		return false
+	}
+	if len(fields) == 0 {
+		return v.confirmConfigCommand(index, configCommand)
+	}
+	title := cmp.Or(configCommand.Description, "Run command")
+	v.openCommandFormModal(index, title, fields, func(values map[string]string) {
+		v.confirmConfigCommand(index, renderConfigCommand(configCommand, values))
+	})
+	return true
+}
+
+// confirmConfigCommand executes a key-bound command of the pane at index, after asking its confirm
+// question if it has one. It reports whether it opened a modal to ask.
+func (v *View) confirmConfigCommand(index int, configCommand *config.ConfigCommand) bool {
+	if configCommand.Confirm == "" {
+		v.executeConfigCommand(index, configCommand)
+		return false
+	}
+	v.openConfirmModal(index, configCommand.Confirm, func() {
+		v.executeConfigCommand(index, configCommand)
+	})
+	return true
+}
+
+// renderConfigCommand returns a copy of configCommand with the placeholders in its command and
+// steps replaced by the shell-quoted values.
+func renderConfigCommand(
+	configCommand *config.ConfigCommand,
+	values map[string]string,
+) *config.ConfigCommand {
+	rendered := *configCommand
+	rendered.Command = placeholder.Render(configCommand.Command, values)
+	rendered.Steps = make([]string, len(configCommand.Steps))
+	for i, step := range configCommand.Steps {
+		rendered.Steps[i] = placeholder.Render(step, values)
+	}
+	return &rendered
+}
+
+// executeConfigCommand runs a key-bound command of the pane at index: its steps, a reserved
+// command, a silent command or a command shown in the command output modal.
+func (v *View) executeConfigCommand(index int, configCommand *config.ConfigCommand) {
+	configPane := v.panes[index].config
+	if len(configCommand.Steps) > 0 {
+		go v.runCommandSteps(index, configCommand.Steps)
+		return
+	}
+	if configCommand.Command == constant.ReservedCommand.TogglePaneSize {
+		v.togglePaneSize()
+		return
+	}
+	if configCommand.Command == constant.ReservedCommand.StartPane {
+		v.startPane(index)
+		return
+	}
+	if configCommand.Command == constant.ReservedCommand.StopPane {
+		v.stopPane(index)
+		return
+	}
+	if configCommand.Command == constant.ReservedCommand.ClearPane {
+		v.clearPane(index)
+		return
+	}
+	if configCommand.Command == constant.ReservedCommand.SavePaneOutput {
+		v.savePaneOutput(index)
+		return
+	}
+	if configCommand.Command == constant.ReservedCommand.GitPanel {
+		if !v.checkIsGitPanelModalOpen() {
+			v.openGitPanelModal(index)
+		}
+		return
+	}
+	if configCommand.Command == constant.ReservedCommand.ShowPaneHistory {
+		if !v.checkIsPaneHistoryModalOpen() {
+			v.openPaneHistoryModal(index)
+		}
+		return
+	}
+	if configCommand.Silent {
+		pane := v.panes[index]
+		sh := shell.Current()
+		cmd := exec.Command(sh, "-c", configCommand.Command)
+		cmd.Env = v.paneCommandEnv(pane)
+		cmd.Dir = configPane.Dir
+		err := cmd.Start()
+		if err != nil {
+			logger.Errorf(
+				"silent command failed to start for pane %s: %v",
+				configPane.Name,
+				err,
+			)
+			v.writePaneMessage(pane, "[red]Command execution failed: %s[white]\n",
+				tview.Escape(err.Error()),
+			)
+		} else {
+			v.writePaneMessage(pane, "[green]Command started successfully: %s[white]\n",
+				tview.Escape(configCommand.Command),
+			)
+			go func() {
+				if err := cmd.Wait(); err != nil {
+					if exitErr, ok := err.(*exec.ExitError); ok {
+						if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() && status.Signal() == unix.SIGKILL {
+							return
+						}
+					}
+					logger.Errorf("silent command execution failed for pane %s: %v", configPane.Name, err)
+					v.queueUpdate(func() {
+						v.writePaneMessage(pane, "[red]Command execution failed: %s[white]\n",
+							tview.Escape(err.Error()),
+						)
+					})
+				}
+			}()
+		}
+		return
+	}
+	if !v.checkIsCommandHelpModalOpen() && !v.checkIsCommandOutputModalOpen() {
+		v.commandOutputModal.callerPaneIndex = index
+		if configCommand.AutoExecute {
+			v.recordCommand(index, configCommand.Command)
+			v.runCustomUserCommand(
+				v.panes[v.commandOutputModal.callerPaneIndex].config.Dir,
+				configCommand.Command,
+			)
+		} else {
+			v.commandOutputModal.inputField = v.openCommandOutputModal()
+			v.commandOutputModal.inputField.SetText(configCommand.Command)
+		}
+		v.disablePanesMouse()
+	}
+}
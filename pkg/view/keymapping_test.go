@@ -1,6 +1,11 @@
 package view
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/jiyeol-lee/localdev/pkg/config"
+	"github.com/rivo/tview"
+)
 
 func Test_keyToFocusAction(t *testing.T) {
 	type args struct {
@@ -71,3 +76,20 @@ func Test_keyToFocusAction(t *testing.T) {
 		})
 	}
 }
+
+func TestView_executeConfigCommand_SilentRecordsMessageInHistory(t *testing.T) {
+	pane := &Pane{
+		textView: tview.NewTextView().SetDynamicColors(true),
+		config:   config.ConfigPane{Name: "api", Dir: t.TempDir()},
+	}
+	v := &View{panes: []*Pane{pane}}
+
+	v.executeConfigCommand(0, &config.ConfigCommand{Command: "echo [x]", Silent: true})
+
+	if got, want := pane.history.text(), "Command started successfully: echo [x]\n"; got != want {
+		t.Fatalf("history = %q, want %q", got, want)
+	}
+	if got, want := pane.textView.GetText(true), "Command started successfully: echo [x]\n"; got != want {
+		t.Fatalf("text = %q, want %q", got, want)
+	}
+}
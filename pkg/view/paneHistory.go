@@ -0,0 +1,42 @@
+package view
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/jiyeol-lee/localdev/pkg/constant"
+)
+
+// paneHistory keeps the raw lines written to a pane, independent of how they are rendered.
+// The zero value is ready to use.
+type paneHistory struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+// append records text, splitting it into lines. A trailing newline terminates the last line.
+func (h *paneHistory) append(text string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.lines = append(h.lines, strings.Split(strings.TrimSuffix(text, "\n"), "\n")...)
+	if overflow := len(h.lines) - constant.MaxPaneHistoryLines; overflow > 0 {
+		h.lines = append([]string(nil), h.lines[overflow:]...)
+	}
+}
+
+// clear removes all recorded lines.
+func (h *paneHistory) clear() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.lines = nil
+}
+
+// text returns the recorded lines joined with newlines.
+func (h *paneHistory) text() string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.lines) == 0 {
+		return ""
+	}
+	return strings.Join(h.lines, "\n") + "\n"
+}
@@ -0,0 +1,18 @@
+package view
+
+import "github.com/rivo/tview"
+
+type paneHistoryModal struct {
+	callerPaneIndex int
+	textView        *tview.TextView
+}
+
+func newPaneHistoryModal() *paneHistoryModal {
+	return &paneHistoryModal{
+		textView: tview.NewTextView(),
+	}
+}
+
+func (p *paneHistoryModal) reset() {
+	p.textView = nil
+}
@@ -0,0 +1,208 @@
+package view
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jiyeol-lee/localdev/internal/logger"
+	"github.com/jiyeol-lee/localdev/pkg/config"
+	"github.com/jiyeol-lee/localdev/pkg/internal/cron"
+	"github.com/jiyeol-lee/localdev/pkg/internal/shell"
+	"github.com/rivo/tview"
+	"golang.org/x/sys/unix"
+)
+
+// maxScheduledFailureLines is how many trailing output lines of a failed scheduled command are
+// written into the pane.
+const maxScheduledFailureLines = 20
+
+// scheduledCommand is a pane command run in the background on a schedule.
+type scheduledCommand struct {
+	config config.ScheduledCommand
+	// schedule is nil for commands run at a fixed interval.
+	schedule *cron.Schedule
+
+	mu       sync.Mutex
+	nextRun  time.Time
+	lastRun  time.Time
+	duration time.Duration
+	// status is the result of the last run: "ok", "exit N" or an error.
+	status string
+}
+
+// newScheduledCommands creates the scheduled commands of a pane. Entries with an invalid cron
+// expression, which the config validation rejects, are skipped.
+func newScheduledCommands(paneName string, entries []config.ScheduledCommand) []*scheduledCommand {
+	var scheduled []*scheduledCommand
+	for _, entry := range entries {
+		sc := &scheduledCommand{config: entry}
+		if entry.Cron != "" {
+			schedule, err := cron.Parse(entry.Cron)
+			if err != nil {
+				logger.Errorf("error parsing schedule of pane %s: %v", paneName, err)
+				continue
+			}
+			sc.schedule = schedule
+		}
+		scheduled = append(scheduled, sc)
+	}
+	return scheduled
+}
+
+// next returns the first run time after t, or the zero time when there is none.
+func (sc *scheduledCommand) next(t time.Time) time.Time {
+	if sc.schedule != nil {
+		return sc.schedule.Next(t)
+	}
+	return t.Add(sc.config.Every)
+}
+
+// label returns the description of the command, or the command itself.
+func (sc *scheduledCommand) label() string {
+	if sc.config.Description != "" {
+		return sc.config.Description
+	}
+	return sc.config.Command
+}
+
+// interval describes when the command runs.
+func (sc *scheduledCommand) interval() string {
+	if sc.schedule != nil {
+		return sc.config.Cron
+	}
+	return "every " + sc.config.Every.String()
+}
+
+// startSchedules runs the scheduled commands of every pane in the background until ctx is done.
+func (v *View) startSchedules(ctx context.Context) {
+	for _, p := range v.panes {
+		for _, sc := range p.scheduled {
+			go v.runSchedule(ctx, p, sc)
+		}
+	}
+}
+
+// runSchedule runs sc whenever it is due until ctx is done. A run that is still going when the
+// command is due again delays the next run instead of overlapping it.
+func (v *View) runSchedule(ctx context.Context, p *Pane, sc *scheduledCommand) {
+	for {
+		next := sc.next(time.Now())
+		sc.mu.Lock()
+		sc.nextRun = next
+		sc.mu.Unlock()
+		if next.IsZero() {
+			return
+		}
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		v.runScheduledCommand(ctx, p, sc)
+	}
+}
+
+// runScheduledCommand runs sc once, silently, in the pane dir with the pane env. A failure is
+// written into the pane with the end of the command output.
+func (v *View) runScheduledCommand(ctx context.Context, p *Pane, sc *scheduledCommand) {
+	cmd := exec.CommandContext(ctx, shell.Current(), "-c", sc.config.Command)
+	cmd.Dir = p.config.Dir
+	cmd.Env = v.paneCommandEnv(p)
+	cmd.SysProcAttr = &unix.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return unix.Kill(-cmd.Process.Pid, unix.SIGKILL)
+	}
+	cmd.WaitDelay = time.Second
+
+	started := time.Now()
+	output, err := cmd.CombinedOutput()
+	if ctx.Err() != nil {
+		// Local Dev is shutting down.
+		return
+	}
+	status := "ok"
+	var exitErr *exec.ExitError
+	switch {
+	case errors.As(err, &exitErr) && exitErr.ExitCode() >= 0:
+		status = fmt.Sprintf("exit %d", exitErr.ExitCode())
+	case err != nil:
+		status = err.Error()
+	}
+
+	sc.mu.Lock()
+	sc.lastRun = started
+	sc.duration = time.Since(started)
+	sc.status = status
+	sc.mu.Unlock()
+
+	if err == nil {
+		return
+	}
+	logger.Errorf("scheduled command %q of pane %s failed: %v", sc.config.Command, p.config.Name, err)
+	v.queueUpdate(func() {
+		v.writePaneMessage(
+			p,
+			"[red]Scheduled command %s failed (%s) at %s[-]\n",
+			tview.Escape(sc.label()),
+			tview.Escape(status),
+			started.Format("15:04:05"),
+		)
+	})
+	lines := strings.Split(strings.TrimRight(string(output), "\n"), "\n")
+	if len(lines) > maxScheduledFailureLines {
+		lines = lines[len(lines)-maxScheduledFailureLines:]
+	}
+	for _, line := range lines {
+		if line != "" {
+			v.writePaneOutput(p, line, true)
+		}
+	}
+}
+
+// writeScheduleSummary lists the scheduled commands of p with their last result and next run.
+func (v *View) writeScheduleSummary(tv *tview.TextView, p *Pane) {
+	if len(p.scheduled) == 0 {
+		return
+	}
+	_, _ = tv.Write(fmt.Appendf(nil, "\n  [orange]===%s===[-]\n\n", "Schedule"))
+	for _, sc := range p.scheduled {
+		sc.mu.Lock()
+		lastRun, duration, status, nextRun := sc.lastRun, sc.duration, sc.status, sc.nextRun
+		sc.mu.Unlock()
+
+		last := "[gray]not run yet[white]"
+		if !lastRun.IsZero() {
+			color := "green"
+			if status != "ok" {
+				color = "red"
+			}
+			last = fmt.Sprintf(
+				"last run %s [%s]%s[white] (%s)",
+				lastRun.Format("15:04:05"),
+				color,
+				tview.Escape(status),
+				formatTaskDuration(duration),
+			)
+		}
+		next := ""
+		if !nextRun.IsZero() {
+			next = ", next " + nextRun.Format("01-02 15:04:05")
+		}
+		_, _ = tv.Write(fmt.Appendf(
+			nil,
+			"  [lightgreen]%s[white] (%s): %s%s\n",
+			tview.Escape(sc.label()),
+			tview.Escape(sc.interval()),
+			last,
+			next,
+		))
+	}
+}
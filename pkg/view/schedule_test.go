@@ -0,0 +1,116 @@
+package view
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jiyeol-lee/localdev/pkg/config"
+	"github.com/rivo/tview"
+)
+
+func TestView_runScheduledCommand(t *testing.T) {
+	tests := []struct {
+		name        string
+		command     string
+		wantStatus  string
+		wantHistory []string
+	}{
+		{name: "success is silent", command: "echo linted", wantStatus: "ok"},
+		{
+			name:       "failure is written into the pane",
+			command:    "echo 'lint error in main.go'; exit 2",
+			wantStatus: "exit 2",
+			wantHistory: []string{
+				"Scheduled command lint failed (exit 2)",
+				"lint error in main.go",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := startTestTviewApplication(t)
+			p := &Pane{
+				textView: tview.NewTextView(),
+				config:   config.ConfigPane{Name: "api", Dir: t.TempDir()},
+			}
+			v := &View{tviewApp: app, panes: []*Pane{p}}
+			sc := newScheduledCommands(p.config.Name, []config.ScheduledCommand{
+				{Command: tt.command, Description: "lint", Every: time.Hour},
+			})[0]
+
+			v.runScheduledCommand(context.Background(), p, sc)
+			waitForUI(t, app, func() bool { return true })
+
+			sc.mu.Lock()
+			status, lastRun := sc.status, sc.lastRun
+			sc.mu.Unlock()
+			if status != tt.wantStatus || lastRun.IsZero() {
+				t.Errorf("status = %q, last run %v, want %q", status, lastRun, tt.wantStatus)
+			}
+			history := p.history.text()
+			if len(tt.wantHistory) == 0 && history != "" {
+				t.Errorf("history = %q, want nothing", history)
+			}
+			for _, want := range tt.wantHistory {
+				if !strings.Contains(history, want) {
+					t.Errorf("history = %q, want %q", history, want)
+				}
+			}
+		})
+	}
+}
+
+func TestView_runSchedule_RunsUntilCanceled(t *testing.T) {
+	app := startTestTviewApplication(t)
+	dir := t.TempDir()
+	p := &Pane{
+		textView: tview.NewTextView(),
+		config:   config.ConfigPane{Name: "api", Dir: dir},
+	}
+	v := &View{tviewApp: app, panes: []*Pane{p}}
+	sc := newScheduledCommands(p.config.Name, []config.ScheduledCommand{
+		{Command: "echo x >> runs", Every: 50 * time.Millisecond},
+	})[0]
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		v.runSchedule(ctx, p, sc)
+		close(done)
+	}()
+	waitForUI(t, app, func() bool {
+		sc.mu.Lock()
+		defer sc.mu.Unlock()
+		return sc.status == "ok" && time.Since(sc.lastRun) < time.Second
+	})
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("runSchedule() did not return after cancel")
+	}
+}
+
+func TestView_writeScheduleSummary(t *testing.T) {
+	p := &Pane{scheduled: newScheduledCommands("api", []config.ScheduledCommand{
+		{Command: "make lint", Cron: "*/15 * * * *"},
+		{Command: "refresh-token", Description: "Refresh token", Every: 5 * time.Minute},
+	})}
+	p.scheduled[0].lastRun = time.Date(2026, time.March, 4, 10, 15, 0, 0, time.Local)
+	p.scheduled[0].duration = 1200 * time.Millisecond
+	p.scheduled[0].status = "exit 2"
+	p.scheduled[0].nextRun = time.Date(2026, time.March, 4, 10, 30, 0, 0, time.Local)
+
+	v := &View{}
+	tv := tview.NewTextView().SetDynamicColors(true)
+	v.writeScheduleSummary(tv, p)
+
+	want := "\n  ===Schedule===\n\n" +
+		"  make lint (*/15 * * * *): last run 10:15:00 exit 2 (1.2s), next 03-04 10:30:00\n" +
+		"  Refresh token (every 5m0s): not run yet\n"
+	if got := tv.GetText(true); got != want {
+		t.Errorf("writeScheduleSummary() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,54 @@
+package view
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// startupView shows the output of the project command while Local Dev starts.
+type startupView struct {
+	textView *tview.TextView
+}
+
+func newStartupView(projectCmd string) *startupView {
+	textView := tview.NewTextView().SetDynamicColors(true).SetScrollable(true)
+	textView.SetBorder(true).SetTitle("Local Dev - Starting")
+	_, _ = fmt.Fprintf(
+		textView,
+		"[gray]Running project command:[-] %s\n\n",
+		tview.Escape(sanitizeForDisplay(projectCmd)),
+	)
+	return &startupView{textView: textView}
+}
+
+// writer returns a writer that appends each line written to it, passed through maskLine, to the
+// startup view, using queueUpdateDraw to run on the UI goroutine. The caller must close it once
+// the output is complete.
+func (s *startupView) writer(queueUpdateDraw func(func()) bool, maskLine func(string) string) io.WriteCloser {
+	return newLineWriter(func(line string) {
+		line = tview.Escape(sanitizeForDisplay(maskLine(line)))
+		queueUpdateDraw(func() {
+			_, _ = fmt.Fprintln(s.textView, line)
+			s.textView.ScrollToEnd()
+		})
+	})
+}
+
+// showFailure reports err and calls exit on the next key press.
+// It must be called on the UI goroutine.
+func (s *startupView) showFailure(err error, exit func()) {
+	_, _ = fmt.Fprintf(
+		s.textView,
+		"\n[red]Startup failed: %s[-]\n[gray]Press any key to exit.[-]\n",
+		tview.Escape(err.Error()),
+	)
+	s.textView.ScrollToEnd()
+	s.textView.SetTitle("Local Dev - Startup Failed")
+	s.textView.SetInputCapture(func(_ *tcell.EventKey) *tcell.EventKey {
+		exit()
+		return nil
+	})
+}
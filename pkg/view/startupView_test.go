@@ -0,0 +1,55 @@
+package view
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestView_runProjectCommand_StreamsOutputAndCapturesEnv(t *testing.T) {
+	app := startTestTviewApplication(t)
+	v := &View{tviewApp: app}
+	startup := newStartupView("source .envrc")
+
+	changes, err := v.runProjectCommand(
+		context.Background(),
+		"echo loading env; TEST_PROJECT_VAR=1; export TEST_PROJECT_VAR",
+		startup,
+	)
+	if err != nil {
+		t.Fatalf("runProjectCommand() error = %v", err)
+	}
+	if !slices.Contains(changes.Added, "TEST_PROJECT_VAR=1") {
+		t.Errorf("Added = %v, want TEST_PROJECT_VAR=1", changes.Added)
+	}
+	waitForUI(t, app, func() bool {
+		return strings.Contains(startup.textView.GetText(true), "loading env\n")
+	})
+}
+
+func TestView_runProjectCommand_ReportsFailure(t *testing.T) {
+	app := startTestTviewApplication(t)
+	v := &View{tviewApp: app}
+	startup := newStartupView("source .envrc")
+
+	// The last command fails without exiting the shell, like a failed source or nvm use.
+	if _, err := v.runProjectCommand(context.Background(), "echo missing file >&2; false", startup); err == nil {
+		t.Fatal("runProjectCommand() error = nil, want the command failure")
+	} else {
+		exited := make(chan struct{}, 1)
+		app.QueueUpdate(func() {
+			startup.showFailure(err, func() { exited <- struct{}{} })
+		})
+		waitForUI(t, app, func() bool {
+			text := startup.textView.GetText(true)
+			return strings.Contains(text, "missing file\n") && strings.Contains(text, "Startup failed")
+		})
+		app.QueueUpdate(func() {
+			startup.textView.InputHandler()(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), nil)
+		})
+		waitForUI(t, app, func() bool { return len(exited) == 1 })
+	}
+}
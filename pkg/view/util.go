@@ -1,10 +1,18 @@
 package view
 
 import (
+	"bufio"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
 	"strings"
+	"time"
 
+	"github.com/jiyeol-lee/localdev/pkg/constant"
 	"golang.org/x/sys/unix"
 	"golang.org/x/term"
 )
@@ -22,6 +30,69 @@ func convertCommandKeyToCharacter(key string) (string, error) {
 	return "", fmt.Errorf("invalid key format: %s", key)
 }
 
+var colorTagRegex = regexp.MustCompile(
+	`\[(?:[a-zA-Z]+|#[0-9a-fA-F]{6}|-)?(?::(?:[a-zA-Z]+|#[0-9a-fA-F]{6}|-)?){0,2}\]`,
+)
+
+// escapedTagRegex matches square brackets escaped with tview.Escape, such as "[red[]".
+var escapedTagRegex = regexp.MustCompile(`(\[[a-zA-Z0-9_,;: \-\."#]*)\[\]`)
+
+// stripColorTags removes tview color tags such as "[red]" or "[-]" from a string and unescapes
+// brackets escaped with tview.Escape.
+func stripColorTags(s string) string {
+	s = colorTagRegex.ReplaceAllStringFunc(s, func(tag string) string {
+		if tag == "[]" {
+			return tag
+		}
+		return ""
+	})
+	return escapedTagRegex.ReplaceAllString(s, "$1]")
+}
+
+// isReservedCommand reports whether the command is one of the built-in reserved commands.
+func isReservedCommand(command string) bool {
+	return slices.Contains([]string{
+		constant.ReservedCommand.TogglePaneSize,
+		constant.ReservedCommand.StartPane,
+		constant.ReservedCommand.StopPane,
+		constant.ReservedCommand.ShowPaneHistory,
+		constant.ReservedCommand.ClearPane,
+		constant.ReservedCommand.SavePaneOutput,
+		constant.ReservedCommand.GitPanel,
+	}, command)
+}
+
+var unsafeFileNameRegex = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// paneOutputFileName builds a timestamped file name for saved pane output. A positive n is added
+// as a suffix to tell apart files saved within the same second.
+func paneOutputFileName(paneName string, t time.Time, n int) string {
+	name := strings.Trim(unsafeFileNameRegex.ReplaceAllString(paneName, "-"), "-")
+	if name == "" {
+		name = "pane"
+	}
+	if n > 0 {
+		return fmt.Sprintf("%s-%s-%d.log", name, t.Format("20060102-150405"), n)
+	}
+	return fmt.Sprintf("%s-%s.log", name, t.Format("20060102-150405"))
+}
+
+// maxPaneOutputFileSuffix bounds the suffixes tried when saved output files collide.
+const maxPaneOutputFileSuffix = 100
+
+// createPaneOutputFile creates a new output file for the named pane in dir without overwriting
+// an existing one.
+func createPaneOutputFile(dir, paneName string, t time.Time) (*os.File, error) {
+	for n := 0; ; n++ {
+		path := filepath.Join(dir, paneOutputFileName(paneName, t, n))
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+		if errors.Is(err, os.ErrExist) && n < maxPaneOutputFileSuffix {
+			continue
+		}
+		return f, err
+	}
+}
+
 // flushInput flushes any buffered input from the terminal.
 func flushInput() error {
 	fd := int(os.Stdin.Fd())
@@ -64,3 +135,33 @@ func flushInput() error {
 
 	return nil
 }
+
+// lineWriter calls onLine for every line written to it.
+type lineWriter struct {
+	*io.PipeWriter
+	done chan struct{}
+}
+
+// newLineWriter returns a writer that calls onLine from a background goroutine for each line
+// written to it. The caller must close it once the output is complete.
+func newLineWriter(onLine func(line string)) *lineWriter {
+	reader, writer := io.Pipe()
+	w := &lineWriter{PipeWriter: writer, done: make(chan struct{})}
+	go func() {
+		defer close(w.done)
+		scanner := bufio.NewScanner(reader)
+		for scanner.Scan() {
+			onLine(scanner.Text())
+		}
+		// Drain the pipe so the writer never blocks on a line too long to scan.
+		_, _ = io.Copy(io.Discard, reader)
+	}()
+	return w
+}
+
+// Close flushes the last line and waits until onLine has been called for every line.
+func (w *lineWriter) Close() error {
+	err := w.PipeWriter.Close()
+	<-w.done
+	return err
+}
@@ -1,6 +1,11 @@
 package view
 
-import "testing"
+import (
+	"testing"
+	"time"
+
+	"github.com/rivo/tview"
+)
 
 func Test_convertCommandKeyToCharacter(t *testing.T) {
 	type args struct {
@@ -66,3 +71,45 @@ func Test_convertCommandKeyToCharacter(t *testing.T) {
 		})
 	}
 }
+
+func Test_stripColorTags(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "named colors", in: "[red]error[-] done", want: "error done"},
+		{name: "hex and attributes", in: "[#8B4513]warn[white::b]x[-:-:-]", want: "warnx"},
+		{name: "plain brackets kept", in: "[1] pane [] [not a tag!]", want: "[1] pane [] [not a tag!]"},
+		{name: "escaped brackets unescaped", in: "[red]" + tview.Escape("failed: [x] [red]") + "[-]", want: "failed: [x] [red]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := stripColorTags(tt.in); got != tt.want {
+				t.Errorf("stripColorTags() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_paneOutputFileName(t *testing.T) {
+	ts := time.Date(2024, 3, 5, 14, 7, 9, 0, time.UTC)
+	tests := []struct {
+		name     string
+		paneName string
+		n        int
+		want     string
+	}{
+		{name: "simple name", paneName: "api", want: "api-20240305-140709.log"},
+		{name: "collision suffix", paneName: "api", n: 2, want: "api-20240305-140709-2.log"},
+		{name: "unsafe characters", paneName: "web / admin", want: "web-admin-20240305-140709.log"},
+		{name: "empty name", paneName: "///", want: "pane-20240305-140709.log"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := paneOutputFileName(tt.paneName, ts, tt.n); got != tt.want {
+				t.Errorf("paneOutputFileName() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,14 +2,19 @@ package view
 
 import (
 	"bufio"
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"os/signal"
 	"path/filepath"
 	"regexp"
+	"slices"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
@@ -19,8 +24,11 @@ import (
 	"github.com/jiyeol-lee/localdev/pkg/config"
 	"github.com/jiyeol-lee/localdev/pkg/constant"
 	"github.com/jiyeol-lee/localdev/pkg/internal/env_vars"
+	"github.com/jiyeol-lee/localdev/pkg/internal/history"
+	"github.com/jiyeol-lee/localdev/pkg/internal/mask"
+	"github.com/jiyeol-lee/localdev/pkg/internal/session"
 	"github.com/jiyeol-lee/localdev/pkg/internal/shell"
-	"github.com/jiyeol-lee/localdev/pkg/util"
+	"github.com/jiyeol-lee/localdev/pkg/internal/signame"
 	"github.com/rivo/tview"
 	"golang.org/x/sys/unix"
 )
@@ -33,10 +41,43 @@ type Pane struct {
 	cmd          *exec.Cmd
 	generation   int
 	stopExecuted bool
+	history      paneHistory
+	// lastBranch is the last settled branch seen for the pane dir; only accessed on the UI goroutine.
+	lastBranch string
+	// env holds the KEY=VALUE entries loaded from the pane env and env_file settings before the
+	// pane first starts.
+	env []string
+	// setupChanges records how the pane setup command changed the environment; guarded by mu.
+	setupChanges env_vars.EnvVarsChanges
+	// cancelSetup cancels the setup command of the latest start; guarded by mu.
+	cancelSetup context.CancelFunc
+
+	// task records the latest run of a task pane; guarded by mu.
+	task taskRun
+	// scheduled holds the pane's scheduled commands.
+	scheduled []*scheduledCommand
+	// runningSteps is set while the steps of a command run in the pane.
+	runningSteps atomic.Bool
 
 	expectedStopGenerations map[int]bool
 }
 
+// taskRun is the state of the latest run of a task pane.
+type taskRun struct {
+	startedAt time.Time
+	finished  bool
+	// exitCode is the exit code of a finished run, or -1 when it did not exit normally.
+	exitCode int
+	duration time.Duration
+	// stopped reports whether the run was stopped with <stop_pane>, <start_pane> or on exit.
+	stopped bool
+}
+
+// succeeded reports whether the run finished with exit code 0.
+func (r taskRun) succeeded() bool {
+	return r.finished && !r.stopped && r.exitCode == 0
+}
+
 func (p *Pane) markExpectedStop(gen int) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
@@ -58,27 +99,62 @@ func (p *Pane) clearExpectedStop(gen int) {
 	delete(p.expectedStopGenerations, gen)
 }
 
+// stopSetup kills the setup command of the latest start, if it is still running.
+func (p *Pane) stopSetup() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.cancelSetup != nil {
+		p.cancelSetup()
+	}
+}
+
 // IsRunning reports whether the pane's start process is currently running.
 func (p *Pane) IsRunning() bool {
 	p.mu.Lock()
 	defer p.mu.Unlock()
+	// p.cmd is cleared once Wait returns; ProcessState is not read here because Wait writes it
+	// without holding p.mu.
 	if p.cmd == nil || p.cmd.Process == nil {
 		return false
 	}
-	if p.cmd.ProcessState != nil {
-		return false
-	}
 	return unix.Kill(-p.cmd.Process.Pid, 0) == nil
 }
 
 // View manages the terminal UI, panes, and user interactions.
 type View struct {
-	tviewApp           *tview.Application
-	tviewPages         *tview.Pages
-	panes              []*Pane
-	envVars            []string
-	commandOutputModal *commandOutputModal
-	commandHelpModal   *commandHelpModal
+	// stopMu guards tviewApp while Run creates it and stopRequested, which Stop sets.
+	stopMu        sync.Mutex
+	stopRequested bool
+	tviewApp      *tview.Application
+	// uiDone is closed once the tview event loop has returned; see uiStopped.
+	uiDone     chan struct{}
+	uiDoneOnce sync.Once
+	tviewPages *tview.Pages
+	panes      []*Pane
+	// envChanges records how project_settings.command changed the environment.
+	envChanges env_vars.EnvVarsChanges
+	// projectEnv holds the KEY=VALUE entries loaded from project_settings env and env_file.
+	projectEnv []string
+	// masker hides secrets in pane output and logs; maskedEnvNames are the globs of env var
+	// names whose values it masks.
+	masker         *mask.Masker
+	maskedEnvNames []string
+	outputDir      string
+	// session records the start process groups so a later launch can find them after a crash.
+	session *session.Recorder
+	// commandHistory keeps the commands run from the command input across sessions.
+	commandHistory  *history.Store
+	gitStatusPoller *command.GitStatusPoller
+	gitFetcher      *command.GitFetcher
+	// fetchingDirs and fetchErrors are keyed by pane dir and only accessed on the UI goroutine.
+	fetchingDirs        map[string]bool
+	fetchErrors         map[string]string
+	commandOutputModal  *commandOutputModal
+	commandHelpModal    *commandHelpModal
+	paneHistoryModal    *paneHistoryModal
+	gitPanelModal       *gitPanelModal
+	commandFormModal    *commandFormModal
+	commandPaletteModal *commandPaletteModal
 }
 
 // getGridDimensions calculates the number of rows and columns for the grid layout
@@ -127,7 +203,7 @@ func (v *View) runCustomUserCommand(dir string, userCmd string) {
 
 		sh := shell.Current()
 		cmd := exec.CommandContext(ctx, sh, "-c", userCmd)
-		cmd.Env = append(os.Environ(), v.envVars...)
+		cmd.Env = v.paneCommandEnv(v.panes[v.commandOutputModal.callerPaneIndex])
 		cmd.Dir = dir
 		cmd.Stdout = os.Stdout
 		cmd.Stderr = os.Stderr
@@ -241,7 +317,7 @@ func (v *View) runCustomUserCommand(dir string, userCmd string) {
 func (v *View) runPaneUserCommand(pane *Pane, generation int) (*exec.Cmd, error) {
 	sh := shell.Current()
 	cmd := exec.Command(sh, "-c", pane.config.Start)
-	cmd.Env = append(os.Environ(), v.envVars...)
+	cmd.Env = v.paneCommandEnv(pane)
 	cmd.Dir = pane.config.Dir
 	cmd.SysProcAttr = &unix.SysProcAttr{Setpgid: true}
 
@@ -267,10 +343,7 @@ func (v *View) runPaneUserCommand(pane *Pane, generation int) (*exec.Cmd, error)
 			if gen != currentGen {
 				return
 			}
-			t := scanner.Text()
-			v.tviewApp.QueueUpdate(func() {
-				_, _ = pane.textView.Write([]byte(t + "\n"))
-			})
+			v.writePaneOutput(pane, scanner.Text(), false)
 		}
 		if err := scanner.Err(); err != nil {
 			logger.Errorf(
@@ -290,10 +363,7 @@ func (v *View) runPaneUserCommand(pane *Pane, generation int) (*exec.Cmd, error)
 			if gen != currentGen {
 				return
 			}
-			t := scanner.Text()
-			v.tviewApp.QueueUpdate(func() {
-				_, _ = pane.textView.Write([]byte("[#8B4513]" + t + "[white]\n"))
-			})
+			v.writePaneOutput(pane, scanner.Text(), true)
 		}
 		if err := scanner.Err(); err != nil {
 			logger.Errorf(
@@ -307,22 +377,195 @@ func (v *View) runPaneUserCommand(pane *Pane, generation int) (*exec.Cmd, error)
 	return cmd, nil
 }
 
+// watchPaneCommand makes the started start command c of generation gen the command of the pane at
+// index and handles its exit in the background.
+func (v *View) watchPaneCommand(index int, c *exec.Cmd, gen int) {
+	p := v.panes[index]
+	p.mu.Lock()
+	p.cmd = c
+	p.task = taskRun{startedAt: time.Now()}
+	p.mu.Unlock()
+	v.recordPaneCommand(p, c)
+
+	go func() {
+		defer p.clearExpectedStop(gen)
+		err := c.Wait()
+		if p.config.IsTask() {
+			v.finishTask(p, gen, err)
+		} else if err != nil {
+			v.handlePaneCommandWaitError(p, "start", gen, err)
+		}
+		v.forgetPaneCommand(p, c)
+		p.mu.Lock()
+		if p.cmd == c {
+			p.cmd = nil
+		}
+		p.mu.Unlock()
+		v.queueUpdate(func() {
+			v.updatePaneTitle(index)
+		})
+	}()
+}
+
+// finishTask records the result of generation gen of a task pane and writes it into the pane.
+func (v *View) finishTask(p *Pane, gen int, err error) {
+	p.mu.Lock()
+	if gen != p.generation {
+		// A restart replaced this run; the new run reports its own result.
+		p.mu.Unlock()
+		return
+	}
+	run := p.task
+	run.finished = true
+	run.duration = time.Since(run.startedAt)
+	run.exitCode = 0
+	run.stopped = p.expectedStopGenerations[gen]
+	var exitErr *exec.ExitError
+	switch {
+	case errors.As(err, &exitErr):
+		run.exitCode = exitErr.ExitCode()
+	case err != nil:
+		run.exitCode = -1
+	}
+	p.task = run
+	p.mu.Unlock()
+
+	if run.exitCode != 0 && !run.stopped {
+		logger.Errorf("task pane %s failed: %v", p.config.Name, err)
+	}
+	v.queueUpdate(func() {
+		switch {
+		case run.stopped:
+			v.writePaneMessage(p, "\n[gray]━━━ Task stopped after %s ━━━[-]\n\n", formatTaskDuration(run.duration))
+		case run.exitCode == 0:
+			v.writePaneMessage(p, "\n[green]━━━ Task succeeded in %s ━━━[-]\n\n", formatTaskDuration(run.duration))
+		case run.exitCode > 0:
+			v.writePaneMessage(
+				p,
+				"\n[red]━━━ Task failed with exit code %d after %s ━━━[-]\n\n",
+				run.exitCode,
+				formatTaskDuration(run.duration),
+			)
+		default:
+			v.writePaneMessage(p, "\n[red]━━━ Task failed after %s: %v ━━━[-]\n\n", formatTaskDuration(run.duration), err)
+		}
+	})
+}
+
+// SetSession sets the recorder of the session state file. It must be called before Run.
+func (v *View) SetSession(recorder *session.Recorder) {
+	v.session = recorder
+}
+
+// SetCommandHistory sets the store of the commands run from the command input.
+func (v *View) SetCommandHistory(store *history.Store) {
+	v.commandHistory = store
+}
+
+// recordPaneCommand records the start process group of pane in the session state file.
+func (v *View) recordPaneCommand(pane *Pane, cmd *exec.Cmd) {
+	if err := v.session.SetPane(pane.config.Name, cmd.Process.Pid); err != nil {
+		logger.Warnf("error recording session state for pane %s: %v", pane.config.Name, err)
+	}
+}
+
+// forgetPaneCommand removes the exited start command of pane from the session state file, unless
+// other members of its process group are still running.
+func (v *View) forgetPaneCommand(pane *Pane, cmd *exec.Cmd) {
+	if command.ProcessGroupAlive(cmd.Process.Pid) {
+		return
+	}
+	if err := v.session.RemovePane(pane.config.Name, cmd.Process.Pid); err != nil {
+		logger.Warnf("error recording session state for pane %s: %v", pane.config.Name, err)
+	}
+}
+
+// writePaneOutput records a raw output line in the pane history and queues its rendered form for display.
+// Lines from panes with the JSON format are pretty-printed; other stderr lines are tinted brown.
+func (v *View) writePaneOutput(pane *Pane, line string, isStderr bool) {
+	line = v.masker.Mask(line)
+	pane.history.append(line + "\n")
+	display := line
+	formatted := false
+	if pane.config.Format == constant.PaneOutputFormat.JSON {
+		display, formatted = formatJSONLogLine(line, pane.config.FormatFields)
+		if !formatted {
+			display = line
+		}
+	}
+	if isStderr && !formatted {
+		display = "[#8B4513]" + display + "[white]"
+	}
+	if !v.queueUpdate(func() {
+		_, _ = pane.textView.Write([]byte(display + "\n"))
+	}) {
+		// Keep the output of panes still stopping visible after the UI has closed.
+		fmt.Printf("[%s] %s\n", pane.config.Name, line)
+	}
+}
+
+// uiStopped returns a channel that is closed once the tview event loop has returned.
+func (v *View) uiStopped() chan struct{} {
+	v.uiDoneOnce.Do(func() {
+		v.uiDone = make(chan struct{})
+	})
+	return v.uiDone
+}
+
+// queueUpdate runs f on the UI goroutine and waits for it to finish. Once the event loop has
+// returned, queued updates never run, so f is skipped and queueUpdate returns false instead of
+// blocking.
+func (v *View) queueUpdate(f func()) bool {
+	stopped := v.uiStopped()
+	select {
+	case <-stopped:
+		return false
+	default:
+	}
+	done := make(chan struct{})
+	go func() {
+		v.tviewApp.QueueUpdate(f)
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-stopped:
+		return false
+	}
+}
+
+// queueUpdateDraw works like queueUpdate and redraws the screen after running f.
+func (v *View) queueUpdateDraw(f func()) bool {
+	return v.queueUpdate(func() {
+		f()
+		v.tviewApp.ForceDraw()
+	})
+}
+
+// writePaneMessage writes a status message to the pane and records it, without color tags, in the pane history.
+// It must be called on the UI goroutine.
+func (v *View) writePaneMessage(pane *Pane, format string, args ...any) {
+	message := v.masker.Mask(fmt.Sprintf(format, args...))
+	pane.history.append(stripColorTags(message))
+	_, _ = pane.textView.Write([]byte(message))
+}
+
 func (v *View) handlePaneCommandWaitError(pane *Pane, phase string, generation int, err error) {
 	if err == nil {
 		return
 	}
 	if exitErr, ok := err.(*exec.ExitError); ok {
 		if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() &&
-			(status.Signal() == unix.SIGINT || status.Signal() == unix.SIGKILL) &&
 			pane.isExpectedStop(generation) {
-			// Silently ignore expected SIGINT/SIGKILL for this process generation.
+			// Silently ignore the stop sequence signals expected for this process generation.
 			return
 		}
 	}
 	logger.Errorf("pane %s %s command exited with error: %v", pane.config.Name, phase, err)
-	v.tviewApp.QueueUpdate(func() {
-		_, _ = fmt.Fprintf(
-			pane.textView,
+	v.queueUpdate(func() {
+		v.writePaneMessage(
+			pane,
 			"[red]Pane %s %s command exited with error: %v[-]\n",
 			pane.config.Name,
 			phase,
@@ -331,75 +574,366 @@ func (v *View) handlePaneCommandWaitError(pane *Pane, phase string, generation i
 	})
 }
 
-// getPaneTitle generates the title for each pane in the grid
+// formatGitStatus renders the git information shown in a pane title: the branch (or the short SHA
+// when HEAD is detached), its upstream with ahead/behind counts, staged/unstaged/untracked/conflicted
+// change counts, the stash count, any in-progress operation and whether a background fetch is running.
+func formatGitStatus(gitStatus *command.GitStatus, fetching bool) string {
+	if gitStatus == nil {
+		return "…"
+	}
+	if !gitStatus.IsRepo {
+		return "N/A"
+	}
+
+	var b strings.Builder
+	switch {
+	case gitStatus.Branch != "":
+		b.WriteString(tview.Escape(gitStatus.Branch))
+	case gitStatus.HeadSHA != "":
+		fmt.Fprintf(&b, "[orange](%s)[white]", gitStatus.HeadSHA)
+	default:
+		b.WriteString("N/A")
+	}
+	if gitStatus.Upstream != "" {
+		fmt.Fprintf(&b, " [gray]%s[white]", tview.Escape(gitStatus.Upstream))
+	}
+	if gitStatus.Sync != nil {
+		fmt.Fprintf(
+			&b,
+			" [yellow]↑%d[white] [yellow]↓%d[white]",
+			gitStatus.Sync.Ahead,
+			gitStatus.Sync.Behind,
+		)
+	}
+	counts := []struct {
+		count int
+		tag   string
+	}{
+		{gitStatus.Staged, "[green]+%d[white]"},
+		{gitStatus.Unstaged, "[red]~%d[white]"},
+		{gitStatus.Untracked, "[gray]?%d[white]"},
+		{gitStatus.Conflicted, "[red]!%d[white]"},
+		{gitStatus.Stashes, "[blue]≡%d[white]"},
+	}
+	for _, c := range counts {
+		if c.count > 0 {
+			b.WriteString(" ")
+			fmt.Fprintf(&b, c.tag, c.count)
+		}
+	}
+	if gitStatus.Operation != "" {
+		fmt.Fprintf(&b, " [red]%s[white]", strings.ToUpper(gitStatus.Operation))
+	}
+	if fetching {
+		b.WriteString(" [gray]⟳[white]")
+	}
+	return b.String()
+}
+
+// getPaneTitle generates the title for each pane in the grid.
+// gitStatus is nil while the pane's git status has not been collected yet.
 func getPaneTitle(
 	paneIndex int,
 	configPane config.ConfigPane,
 	focused bool,
 	isRunning bool,
+	task taskRun,
+	gitStatus *command.GitStatus,
+	fetching bool,
 ) string {
-	branch, err := command.GetCurrentBranch(configPane.Dir)
-	branchInfo := branch
-	// if git is not initialized, it will return an error
-	if err != nil {
-		branchInfo = "N/A"
-	}
-	status, err := command.GetBranchSyncStatus(configPane.Dir)
-	// if git is not pushed to remote, it will return an error
-	if err == nil {
-		branchInfo += fmt.Sprintf(
-			" [yellow]↑%d[white] [yellow]↓%d[white]",
-			status.Ahead,
-			status.Behind,
-		)
-	}
+	branchInfo := formatGitStatus(gitStatus, fetching)
 
 	statusIndicator := ""
-	if isRunning {
+	taskStatus := ""
+	switch {
+	case configPane.IsTask():
+		statusIndicator, taskStatus = formatTaskStatus(task, isRunning)
+	case isRunning:
 		statusIndicator = "[green]●[white] "
-	} else {
+	default:
 		statusIndicator = "[red]●[white] "
 	}
 
 	if focused {
 		return fmt.Sprintf(
-			"[green][%d] %s[green]%s[white] - %s",
+			"[green][%d] %s[green]%s[white]%s - %s",
 			paneIndex+1,
 			statusIndicator,
 			configPane.Name,
+			taskStatus,
 			branchInfo,
 		)
 	}
 
-	return fmt.Sprintf("[%d] %s%s - %s", paneIndex+1, statusIndicator, configPane.Name, branchInfo)
+	return fmt.Sprintf(
+		"[%d] %s%s%s - %s",
+		paneIndex+1,
+		statusIndicator,
+		configPane.Name,
+		taskStatus,
+		branchInfo,
+	)
+}
+
+// formatTaskStatus returns the status indicator shown before the name of a task pane and the
+// result shown after it: the exit code and duration of the latest run.
+func formatTaskStatus(task taskRun, isRunning bool) (indicator, status string) {
+	switch {
+	case isRunning || task.startedAt.IsZero():
+		return "[yellow]●[white] ", ""
+	case task.stopped:
+		return "[gray]■[white] ", fmt.Sprintf(" [gray](stopped after %s)[white]", formatTaskDuration(task.duration))
+	case task.exitCode == 0:
+		return "[green]✓[white] ", fmt.Sprintf(" [green](ok in %s)[white]", formatTaskDuration(task.duration))
+	case task.exitCode > 0:
+		return "[red]✗[white] ", fmt.Sprintf(
+			" [red](exit %d after %s)[white]",
+			task.exitCode,
+			formatTaskDuration(task.duration),
+		)
+	default:
+		return "[red]✗[white] ", fmt.Sprintf(" [red](failed after %s)[white]", formatTaskDuration(task.duration))
+	}
 }
 
-// GetEnvVars returns the environment variables captured after running the project command.
-func (v *View) GetEnvVars() []string {
-	return v.envVars
+// formatTaskDuration rounds d for display: to tenths of a second under a minute, to seconds above.
+func formatTaskDuration(d time.Duration) string {
+	if d < time.Minute {
+		return d.Round(100 * time.Millisecond).String()
+	}
+	return d.Round(time.Second).String()
+}
+
+// MaskSecrets returns s with the configured secrets masked.
+func (v *View) MaskSecrets(s string) string {
+	return v.masker.Mask(s)
+}
+
+// addMaskedEnv registers the values of the env vars in env whose names match the mask settings.
+func (v *View) addMaskedEnv(env []string) {
+	if v.masker == nil {
+		return
+	}
+	for _, kv := range env {
+		name, value, _ := strings.Cut(kv, "=")
+		if mask.MatchName(v.maskedEnvNames, name) {
+			v.masker.AddValues(value)
+		}
+	}
+}
+
+// GetPaneCommandEnv returns the environment for the commands of the pane at index.
+func (v *View) GetPaneCommandEnv(index int) []string {
+	return v.paneCommandEnv(v.panes[index])
+}
+
+// commandEnv returns the project-level environment: the current process environment with the
+// changes made by the project command applied, overridden by the project env settings.
+func (v *View) commandEnv() []string {
+	return env_vars.Set(v.envChanges.Apply(os.Environ()), v.projectEnv)
+}
+
+// paneCommandEnv returns the environment for the commands of p: the project-level environment
+// overridden by the pane env settings, with the changes made by the pane setup command applied.
+func (v *View) paneCommandEnv(p *Pane) []string {
+	p.mu.Lock()
+	setupChanges := p.setupChanges
+	p.mu.Unlock()
+	return setupChanges.Apply(v.paneSetupEnv(p))
+}
+
+// paneSetupEnv returns the environment the pane setup command runs with.
+func (v *View) paneSetupEnv(p *Pane) []string {
+	return env_vars.Set(v.commandEnv(), p.env)
+}
+
+// runPaneSetup runs the setup command of p, if any, streaming its output into the pane and
+// recording the env vars it changes for the pane's commands. The setup command is killed when ctx
+// is done.
+func (v *View) runPaneSetup(ctx context.Context, p *Pane) error {
+	if p.config.Setup == "" {
+		return nil
+	}
+	v.queueUpdate(func() {
+		v.writePaneMessage(p, "[gray]━━━ Running setup ━━━[-]\n")
+	})
+	output := newLineWriter(func(line string) {
+		v.writePaneOutput(p, line, false)
+	})
+	changes, err := env_vars.CaptureEnvVarsChanges(
+		ctx,
+		p.config.Setup,
+		p.config.Dir,
+		v.paneSetupEnv(p),
+		output,
+	)
+	_ = output.Close()
+	if err != nil {
+		return fmt.Errorf("error running setup command: %w", err)
+	}
+	p.mu.Lock()
+	p.setupChanges = changes
+	p.mu.Unlock()
+	v.addMaskedEnv(v.paneCommandEnv(p))
+	return nil
 }
 
 // Run initializes and starts the terminal UI with the given configuration.
 func (v *View) Run(config config.Config) error {
+	v.stopMu.Lock()
+	if v.stopRequested {
+		v.stopMu.Unlock()
+		return errors.New("stopped before startup")
+	}
 	v.tviewApp = tview.NewApplication()
+	v.stopMu.Unlock()
 	v.tviewApp.EnableMouse(true).EnablePaste(true).SetInputCapture(v.keyMapping)
 	v.tviewPages, v.panes = v.getRootView(config)
+	outputDir, err := config.GetOutputDir()
+	if err != nil {
+		return fmt.Errorf("error resolving output directory: %w", err)
+	}
+	v.outputDir = outputDir
+	maskedEnvNames, maskPatterns := config.GetMaskSettings()
+	v.masker, err = mask.New(maskPatterns)
+	if err != nil {
+		return fmt.Errorf("error compiling mask patterns: %w", err)
+	}
+	v.maskedEnvNames = maskedEnvNames
+	logger.SetRedactor(v.masker.Mask)
+	paneDirs := make([]string, len(v.panes))
+	for i, pane := range v.panes {
+		paneDirs[i] = pane.config.Dir
+	}
+	v.gitStatusPoller = command.NewGitStatusPoller(
+		paneDirs,
+		constant.GitStatusRefreshInterval,
+		v.handleGitStatusChange,
+	)
+	v.gitStatusPoller.Start()
+	defer v.gitStatusPoller.Stop()
+	v.commandOutputModal = newCommandOutputModal()
+	v.commandHelpModal = newCommandHelpModal()
+	v.paneHistoryModal = newPaneHistoryModal()
+	v.gitPanelModal = newGitPanelModal()
+	v.commandFormModal = newCommandFormModal()
+	v.commandPaletteModal = newCommandPaletteModal()
+
+	// startupErr is only accessed on the UI goroutine until the app stops.
+	var startupErr error
+	started := false
+	scheduleCtx, cancelSchedules := context.WithCancel(context.Background())
+	defer cancelSchedules()
+	startPanes := func() {
+		if err := v.startPanes(config, paneDirs); err != nil {
+			startupErr = err
+			v.tviewApp.Stop()
+			return
+		}
+		v.startSchedules(scheduleCtx)
+		started = true
+		v.tviewApp.SetRoot(v.tviewPages, true)
+	}
+
 	projectCmd := config.GetProjectCommand()
-	if projectCmd != "" {
-		beforeCommandEnvVars, afterCommandEnvVars, err := env_vars.RunCommandAndCaptureEnvVars(
-			projectCmd,
-		)
-		defer os.Remove(beforeCommandEnvVars)
-		defer os.Remove(afterCommandEnvVars)
-		if err != nil {
-			return fmt.Errorf("error running project command: %w", err)
+	if projectCmd == "" {
+		startPanes()
+	} else {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		startup := newStartupView(projectCmd)
+		v.tviewApp.SetRoot(startup.textView, true)
+		go func() {
+			changes, err := v.runProjectCommand(ctx, projectCmd, startup)
+			v.queueUpdateDraw(func() {
+				if err != nil {
+					startupErr = err
+					startup.showFailure(err, v.tviewApp.Stop)
+					return
+				}
+				v.envChanges = changes
+				startPanes()
+			})
+		}()
+	}
+
+	defer func() {
+		if v.gitFetcher != nil {
+			v.gitFetcher.Stop()
 		}
-		v.envVars, err = env_vars.GetDiffEnvVars(beforeCommandEnvVars, afterCommandEnvVars)
+	}()
+	err = v.tviewApp.Run()
+	close(v.uiStopped())
+	if err != nil {
+		return fmt.Errorf("error running app: %w", err)
+	}
+	if startupErr != nil {
+		// Panes started before the failure would otherwise be left running.
+		v.terminateStartedPanes()
+		return startupErr
+	}
+	if !started {
+		return errors.New("startup interrupted before panes were started")
+	}
+	return nil
+}
+
+// runProjectCommand runs the project command, streaming its output into the startup view, and
+// returns how it changed the environment.
+func (v *View) runProjectCommand(
+	ctx context.Context,
+	projectCmd string,
+	startup *startupView,
+) (env_vars.EnvVarsChanges, error) {
+	output := startup.writer(v.queueUpdateDraw, v.masker.Mask)
+	changes, err := env_vars.CaptureEnvVarsChanges(ctx, projectCmd, "", nil, output)
+	_ = output.Close()
+	if err != nil {
+		return env_vars.EnvVarsChanges{}, fmt.Errorf("error running project command: %w", err)
+	}
+	logger.Infof(
+		"project command added %d, changed %d and removed %d env vars",
+		len(changes.Added),
+		len(changes.Changed),
+		len(changes.Removed),
+	)
+	return changes, nil
+}
+
+// startPanes starts the background git fetcher and the start command of every pane.
+// It must be called on the UI goroutine once the project command has finished.
+func (v *View) startPanes(config config.Config, paneDirs []string) error {
+	projectVars, projectEnvFiles := config.GetProjectEnv()
+	projectEnv, err := env_vars.Load(projectVars, projectEnvFiles, config.GetProjectDir())
+	if err != nil {
+		return fmt.Errorf("error loading project env: %w", err)
+	}
+	v.projectEnv = projectEnv
+	for _, pane := range v.panes {
+		pane.env, err = env_vars.Load(pane.config.Env, pane.config.EnvFile, pane.config.Dir)
 		if err != nil {
-			return fmt.Errorf("error getting env vars diff: %w", err)
+			return fmt.Errorf("error loading env for pane %s: %w", pane.config.Name, err)
 		}
+		v.addMaskedEnv(v.paneCommandEnv(pane))
+	}
+
+	if fetchInterval := config.GetGitFetchInterval(); fetchInterval > 0 {
+		v.gitFetcher = command.NewGitFetcher(
+			paneDirs,
+			fetchInterval,
+			v.commandEnv(),
+			v.handleGitFetchStart,
+			v.handleGitFetchDone,
+		)
+		v.gitFetcher.Start()
 	}
 	for i, pane := range v.panes {
+		if pane.config.Setup != "" {
+			// The setup command may take a while, so the pane starts in the background.
+			v.startPane(i)
+			continue
+		}
+
 		pane.mu.Lock()
 		pane.generation++
 		gen := pane.generation
@@ -415,33 +949,10 @@ func (v *View) Run(config config.Config) error {
 			return fmt.Errorf("error running command: %w", err)
 		}
 
-		pane.mu.Lock()
-		pane.cmd = cmd
-		pane.mu.Unlock()
-
-		go func(p *Pane, c *exec.Cmd, gen int, idx int) {
-			defer p.clearExpectedStop(gen)
-			if err := c.Wait(); err != nil {
-				v.handlePaneCommandWaitError(p, "start", gen, err)
-			}
-			p.mu.Lock()
-			if p.cmd == c {
-				p.cmd = nil
-			}
-			p.mu.Unlock()
-			v.tviewApp.QueueUpdate(func() {
-				v.updatePaneTitle(idx)
-			})
-		}(pane, cmd, gen, i)
+		v.watchPaneCommand(i, cmd, gen)
 
 		v.updatePaneTitle(i)
 	}
-	v.tviewApp.SetRoot(v.tviewPages, true)
-	v.commandOutputModal = newCommandOutputModal()
-	v.commandHelpModal = newCommandHelpModal()
-	if err := v.tviewApp.Run(); err != nil {
-		return fmt.Errorf("error running app: %w", err)
-	}
 	return nil
 }
 
@@ -470,21 +981,21 @@ func (v *View) getRootView(config config.Config) (*tview.Pages, []*Pane) {
 			}).ScrollToEnd().SetMaxLines(constant.MaxPaneOutputLines)
 		tv.
 			SetBorder(true).
-			SetTitle(getPaneTitle(index, configPane, tv.HasFocus(), false))
+			SetTitle(getPaneTitle(index, configPane, tv.HasFocus(), false, taskRun{}, nil, false))
 
 		panes[index] = &Pane{
-			textView: tv,
-			config:   configPane,
+			textView:  tv,
+			config:    configPane,
+			scheduled: newScheduledCommands(configPane.Name, configPane.Schedule),
 		}
 		paneRef := panes[index]
 
+		// The blur func runs before the text view loses focus, so the focus state is passed explicitly.
 		tv.SetBlurFunc(func() {
-			tv.SetBorderColor(tcell.ColorWhite).
-				SetTitle(getPaneTitle(index, configPane, false, paneRef.IsRunning()))
+			tv.SetBorderColor(tcell.ColorWhite).SetTitle(v.paneTitle(paneRef, index, false))
 		})
 		tv.SetFocusFunc(func() {
-			tv.SetBorderColor(tcell.ColorGreen).
-				SetTitle(getPaneTitle(index, configPane, true, paneRef.IsRunning()))
+			tv.SetBorderColor(tcell.ColorGreen).SetTitle(v.paneTitle(paneRef, index, true))
 		})
 
 		grid.AddItem(tv, row, col, 1, 1, 0, 0, true)
@@ -536,13 +1047,35 @@ func (v *View) removeCommandHelpModal() {
 	v.enablePanesMouse()
 }
 
+func (v *View) checkIsPaneHistoryModalOpen() bool {
+	return v.tviewPages.HasPage(constant.Page.PaneHistoryModalPage)
+}
+
+func (v *View) removePaneHistoryModal() {
+	v.tviewPages.RemovePage(constant.Page.PaneHistoryModalPage)
+	v.paneHistoryModal.reset()
+	v.enablePanesMouse()
+}
+
 func (v *View) openCommandOutputModal() *tview.InputField {
 	inputField := tview.NewInputField().
 		SetFieldWidth(0).
 		SetFieldBackgroundColor(tcell.ColorBlack)
-	inputField.SetTitle("Command to run").
+	v.commandOutputModal.commandHistory = v.commandHistory.Pane(
+		v.panes[v.commandOutputModal.callerPaneIndex].config.Name,
+	)
+	inputField.SetTitle(commandOutputModalTitle).
 		SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+			if v.commandOutputModal.searching {
+				if event = v.handleCommandSearchKey(inputField, event); event == nil {
+					return nil
+				}
+			}
 			switch event.Key() {
+			case tcell.KeyCtrlR:
+				v.startCommandSearch(inputField)
+				return nil
+
 			case tcell.KeyEsc:
 				callerPaneTextView := v.panes[v.commandOutputModal.callerPaneIndex].textView
 				v.removeCommandOutputModal()
@@ -569,7 +1102,7 @@ func (v *View) openCommandOutputModal() *tview.InputField {
 
 			case tcell.KeyEnter:
 				command := inputField.GetText()
-				v.commandOutputModal.appendCommandHistory(command)
+				v.recordCommand(v.commandOutputModal.callerPaneIndex, command)
 				v.commandOutputModal.resetCommandHistoryIndex()
 				v.runCustomUserCommand(
 					v.panes[v.commandOutputModal.callerPaneIndex].config.Dir,
@@ -604,140 +1137,138 @@ func (v *View) openCommandOutputModal() *tview.InputField {
 	return inputField
 }
 
-func (v *View) openCommandHelpModal() *tview.TextView {
-	textView := tview.NewTextView().ScrollToEnd().SetDynamicColors(true)
+// writeEnvChangesSummary lists the names of the variables the project command added, changed and
+// removed. Values are left out because they often hold credentials.
+func (v *View) writeEnvChangesSummary(tv *tview.TextView) {
+	if v.envChanges.IsEmpty() {
+		return
+	}
+	_, _ = tv.Write(fmt.Appendf(nil, "\n  [orange]===%s===[-]\n\n", "Project Environment"))
+	for _, kv := range v.envChanges.Added {
+		name, _, _ := strings.Cut(kv, "=")
+		_, _ = tv.Write(fmt.Appendf(nil, "  [green]+[white] %s\n", tview.Escape(name)))
+	}
+	for _, kv := range v.envChanges.Changed {
+		name, _, _ := strings.Cut(kv, "=")
+		_, _ = tv.Write(fmt.Appendf(nil, "  [yellow]~[white] %s\n", tview.Escape(name)))
+	}
+	for _, name := range v.envChanges.Removed {
+		_, _ = tv.Write(fmt.Appendf(nil, "  [red]-[white] %s\n", tview.Escape(name)))
+	}
+}
+
+// openPaneHistoryModal shows the full raw output history of the pane at index.
+func (v *View) openPaneHistoryModal(index int) {
+	pane := v.panes[index]
+	textView := tview.NewTextView().SetScrollable(true)
+	textView.SetText(pane.history.text()).ScrollToEnd()
 	textView.
 		SetBorder(true).
-		SetTitle("Command Help").
-		SetMouseCapture(
-			func(action tview.MouseAction, event *tcell.EventMouse) (tview.MouseAction, *tcell.EventMouse) {
-				if action == tview.MouseScrollUp || action == tview.MouseScrollDown {
-					return action, event
-				}
-
-				return tview.MouseConsumed, nil
-			},
-		).
+		SetTitle(fmt.Sprintf("%s - Full History", pane.config.Name)).
 		SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
-			switch event.Key() {
-			case tcell.KeyEsc:
-				callerPaneTextView := v.panes[v.commandHelpModal.callerPaneIndex].textView
-				v.removeCommandHelpModal()
+			if event.Key() == tcell.KeyEsc {
+				callerPaneTextView := v.panes[v.paneHistoryModal.callerPaneIndex].textView
+				v.removePaneHistoryModal()
 				v.tviewApp.SetFocus(callerPaneTextView)
+				return nil
 			}
-			return nil
+			return event
 		})
 	modal := func(p tview.Primitive) *tview.Grid {
-		g := tview.NewGrid()
-		g.SetDrawFunc(func(screen tcell.Screen, x, y, width, height int) (int, int, int, int) {
-			if width > 90 {
-				g.SetColumns(0, 80, 0)
-			} else {
-				g.SetColumns(2, 0, 2)
-			}
-			return x, y, width, height
-		})
-
-		return g.
-			SetRows(2, 0, 2).
+		return tview.NewGrid().
+			SetColumns(2, 0, 2).
+			SetRows(1, 0, 1).
 			AddItem(p, 1, 1, 1, 1, 0, 0, true)
 	}
 
-	v.tviewPages.AddPage(constant.Page.CommandHelpModalPage, modal(textView), true, true)
-
-	return textView
+	v.paneHistoryModal.callerPaneIndex = index
+	v.paneHistoryModal.textView = textView
+	v.tviewPages.AddPage(constant.Page.PaneHistoryModalPage, modal(textView), true, true)
+	v.disablePanesMouse()
 }
 
-func (v *View) setCommandHelpModalBodyText() {
-	tv := v.commandHelpModal.textView
-
-	tv.Clear()
-	_, _ = tv.Write(fmt.Appendf(nil, "\n  [orange]===%s===[-]\n\n", "Local"))
-	_, _ = tv.Write(fmt.Appendf(nil, "  [lightgreen]Silent[-] command\n"))
-	_, _ = tv.Write(fmt.Appendf(nil, "  [green]Normal[-] command\n\n"))
+// clearPane wipes the visible output and the history of the pane at index.
+func (v *View) clearPane(index int) {
+	p := v.panes[index]
+	p.history.clear()
+	p.textView.Clear()
+}
 
-	paneCommands := v.panes[v.commandHelpModal.callerPaneIndex].config.Commands
-	if paneCommands == nil {
-		_, _ = tv.Write(fmt.Appendf(nil, "  No commands available\n"))
+// savePaneOutput writes the history of the pane at index, without color tags, to a timestamped
+// file in the output directory and prints the file path in the pane.
+func (v *View) savePaneOutput(index int) {
+	p := v.panes[index]
+	if err := os.MkdirAll(v.outputDir, 0o755); err != nil {
+		logger.Errorf("error creating output directory for pane %s: %v", p.config.Name, err)
+		v.writePaneMessage(p, "[red]Failed to save output: %s[-]\n", tview.Escape(err.Error()))
 		return
 	}
-	paneCommandsMap, err := util.YamlToMap[*config.ConfigCommands, *config.ConfigCommand](
-		paneCommands,
-	)
+	f, err := createPaneOutputFile(v.outputDir, p.config.Name, time.Now())
+	if err == nil {
+		_, err = f.WriteString(p.history.text())
+		err = errors.Join(err, f.Close())
+	}
 	if err != nil {
-		logger.Errorf(
-			"error rendering command help for pane %s: %v",
-			v.panes[v.commandHelpModal.callerPaneIndex].config.Name,
-			err,
-		)
-		_, _ = tv.Write(fmt.Appendf(nil, "  [red]Error[white]: %s\n", err))
+		logger.Errorf("error saving output for pane %s: %v", p.config.Name, err)
+		v.writePaneMessage(p, "[red]Failed to save output: %s[-]\n", tview.Escape(err.Error()))
 		return
 	}
+	v.writePaneMessage(p, "[gray]━━━ Output saved to %s ━━━[-]\n", tview.Escape(f.Name()))
+}
 
-	// Print non-reserved commands first
-	for key, configCommand := range paneCommandsMap {
-		if configCommand == nil {
-			continue
-		}
-		if configCommand.Command == constant.ReservedCommand.TogglePaneSize ||
-			configCommand.Command == constant.ReservedCommand.StartPane ||
-			configCommand.Command == constant.ReservedCommand.StopPane {
-			continue
-		}
-		c, err := convertCommandKeyToCharacter(key)
-		if err != nil {
-			logger.Errorf(
-				"error rendering command help key %q for pane %s: %v",
-				key,
-				v.panes[v.commandHelpModal.callerPaneIndex].config.Name,
-				err,
-			)
-			_, _ = tv.Write(fmt.Appendf(nil, "  [red]Error[white]: %s\n", err))
-			continue
-		}
-		if configCommand.Silent {
-			_, _ = tv.Write(
-				fmt.Appendf(nil, "  [lightgreen]%s[white] %s\n", c, configCommand.Description),
-			)
-		} else {
-			_, _ = tv.Write(fmt.Appendf(nil, "  [green]%s[white] %s\n", c, configCommand.Description))
-		}
+// PaneStopSteps returns the stop sequence of a pane: its stop signal and timeout followed by its
+// escalation steps.
+func PaneStopSteps(configPane config.ConfigPane) []command.StopStep {
+	signal, err := signame.Parse(cmp.Or(configPane.StopSignal, constant.DefaultStopSignal))
+	if err != nil {
+		signal = unix.SIGINT
 	}
-
-	// Show reserved commands section if any are bound
-	var reservedKeys []string
-	for key, configCommand := range paneCommandsMap {
-		if configCommand == nil {
-			continue
-		}
-		if configCommand.Command == constant.ReservedCommand.TogglePaneSize ||
-			configCommand.Command == constant.ReservedCommand.StartPane ||
-			configCommand.Command == constant.ReservedCommand.StopPane {
-			reservedKeys = append(reservedKeys, key)
+	steps := []command.StopStep{{
+		Signal:  signal,
+		Timeout: cmp.Or(configPane.StopTimeout, constant.DefaultStopTimeout),
+	}}
+	for _, step := range configPane.StopEscalation {
+		if signal, err := signame.Parse(step.Signal); err == nil {
+			steps = append(steps, command.StopStep{
+				Signal:  signal,
+				Timeout: cmp.Or(step.Timeout, constant.DefaultStopTimeout),
+			})
 		}
 	}
+	return steps
+}
 
-	if len(reservedKeys) > 0 {
-		_, _ = tv.Write(fmt.Appendf(nil, "\n  [orange]===%s===[-]\n\n", "Reserved"))
-
-		for _, key := range reservedKeys {
-			configCommand := paneCommandsMap[key]
-			c, err := convertCommandKeyToCharacter(key)
-			if err != nil {
-				logger.Errorf(
-					"error rendering reserved command help key %q for pane %s: %v",
-					key,
-					v.panes[v.commandHelpModal.callerPaneIndex].config.Name,
-					err,
-				)
-				_, _ = tv.Write(fmt.Appendf(nil, "  [red]Error[white]: %s\n", err))
-				continue
-			}
-			_, _ = tv.Write(
-				fmt.Appendf(nil, "  [orange]%s[white] %s\n", c, configCommand.Description),
+// terminatePaneProcessGroup stops the process group pgid of p with the pane's stop sequence,
+// writing each step into the pane. It reports whether the group exited.
+func (v *View) terminatePaneProcessGroup(p *Pane, pgid int) bool {
+	prefix := "Sending"
+	exited, err := command.TerminateProcessGroup(
+		pgid,
+		PaneStopSteps(p.config),
+		func(step command.StopStep) {
+			message := fmt.Sprintf(
+				"%s %s to process group %d (waiting up to %s)",
+				prefix,
+				unix.SignalName(step.Signal),
+				pgid,
+				step.Timeout,
 			)
-		}
+			prefix = "Still running; sending"
+			v.queueUpdate(func() {
+				v.writePaneMessage(p, "[gray]%s[-]\n", message)
+			})
+		},
+	)
+	if err != nil {
+		logger.Warnf("failed to stop process group for pane %s (pgid=%d): %v", p.config.Name, pgid, err)
 	}
+	if !exited {
+		logger.Warnf("process group for pane %s (pgid=%d) is still running", p.config.Name, pgid)
+		v.queueUpdate(func() {
+			v.writePaneMessage(p, "[red]Process group %d is still running[-]\n", pgid)
+		})
+	}
+	return exited
 }
 
 // togglePaneSize maximizes the currently focused pane or restores it back to the grid view
@@ -767,174 +1298,146 @@ func (v *View) checkIsPaneMaximized() bool {
 	return fpName == constant.Page.MaximizedPane
 }
 
+// startPane restarts the pane at index in the background.
 func (v *View) startPane(index int) {
-	p := v.panes[index]
-
-	go func() {
-		p.mu.Lock()
-		oldCmd := p.cmd
-		oldGen := p.generation
-		p.generation++
-		gen := p.generation
-		p.stopExecuted = false
-		p.mu.Unlock()
-
-		v.tviewApp.QueueUpdate(func() {
-			_, _ = fmt.Fprintf(
-				p.textView,
-				"\n[gray]━━━ Started at %s ━━━[-]\n\n",
-				time.Now().Format("15:04:05"),
-			)
-		})
+	go v.startPaneSync(index)
+}
 
-		if oldCmd != nil && oldCmd.Process != nil {
-			p.markExpectedStop(oldGen)
-			pid := oldCmd.Process.Pid
-			if err := unix.Kill(-pid, unix.SIGKILL); err != nil && err != syscall.ESRCH {
-				logger.Warnf(
-					"failed to send SIGKILL during restart cleanup for pane %s (pid=%d, pgid=%d): %v",
-					p.config.Name,
-					pid,
-					-pid,
-					err,
-				)
-			}
-			for range 50 {
-				if err := unix.Kill(-pid, 0); err == syscall.ESRCH {
-					break
-				}
-				time.Sleep(100 * time.Millisecond)
-			}
-		}
+// startPaneSync stops the running start command of the pane at index, runs its setup command and
+// starts it again. It reports whether the start command was started.
+func (v *View) startPaneSync(index int) bool {
+	p := v.panes[index]
 
-		newCmd, err := v.runPaneUserCommand(p, gen)
-		if err != nil {
-			logger.Errorf("error restarting start command for pane %s: %v", p.config.Name, err)
-			v.tviewApp.QueueUpdate(func() {
-				_, _ = fmt.Fprintf(p.textView, "[red]Failed to start: %s[-]\n", err)
-			})
-			v.tviewApp.QueueUpdate(func() {
-				v.updatePaneTitle(index)
-			})
-			return
-		}
+	// Stopping, restarting or shutting down the pane cancels ctx and so kills its setup command.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p.mu.Lock()
+	if p.cancelSetup != nil {
+		p.cancelSetup()
+	}
+	p.cancelSetup = cancel
+	oldCmd := p.cmd
+	oldGen := p.generation
+	p.generation++
+	gen := p.generation
+	p.stopExecuted = false
+	p.mu.Unlock()
+
+	v.queueUpdate(func() {
+		v.writePaneMessage(
+			p,
+			"\n[gray]━━━ Started at %s ━━━[-]\n\n",
+			time.Now().Format("15:04:05"),
+		)
+	})
 
-		p.mu.Lock()
-		p.cmd = newCmd
-		p.mu.Unlock()
+	if oldCmd != nil && oldCmd.Process != nil {
+		p.markExpectedStop(oldGen)
+		v.terminatePaneProcessGroup(p, oldCmd.Process.Pid)
+	}
 
-		go func(p *Pane, c *exec.Cmd, gen int) {
-			defer p.clearExpectedStop(gen)
-			if err := c.Wait(); err != nil {
-				v.handlePaneCommandWaitError(p, "start", gen, err)
-			}
-			p.mu.Lock()
-			if p.cmd == c {
-				p.cmd = nil
-			}
-			p.mu.Unlock()
-			v.tviewApp.QueueUpdate(func() {
-				v.updatePaneTitle(index)
-			})
-		}(p, newCmd, gen)
+	err := v.runPaneSetup(ctx, p)
+	if ctx.Err() != nil {
+		logger.Infof("setup command for pane %s was canceled", p.config.Name)
+		v.queueUpdate(func() {
+			v.writePaneMessage(p, "[gray]Setup canceled[-]\n")
+		})
+		v.queueUpdate(func() {
+			v.updatePaneTitle(index)
+		})
+		return false
+	}
+	if err != nil {
+		logger.Errorf("error running setup command for pane %s: %v", p.config.Name, err)
+		v.queueUpdate(func() {
+			v.writePaneMessage(p, "[red]Setup failed: %s[-]\n", tview.Escape(err.Error()))
+		})
+		v.queueUpdate(func() {
+			v.updatePaneTitle(index)
+		})
+		return false
+	}
 
-		v.tviewApp.QueueUpdate(func() {
+	newCmd, err := v.runPaneUserCommand(p, gen)
+	if err != nil {
+		logger.Errorf("error restarting start command for pane %s: %v", p.config.Name, err)
+		v.queueUpdate(func() {
+			v.writePaneMessage(p, "[red]Failed to start: %s[-]\n", tview.Escape(err.Error()))
+		})
+		v.queueUpdate(func() {
 			v.updatePaneTitle(index)
 		})
-	}()
+		return false
+	}
+
+	v.watchPaneCommand(index, newCmd, gen)
+
+	v.queueUpdate(func() {
+		v.updatePaneTitle(index)
+	})
+	return true
 }
 
+// stopPane stops the pane at index in the background.
 func (v *View) stopPane(index int) {
-	p := v.panes[index]
+	go v.stopPaneSync(index)
+}
 
-	go func() {
-		p.mu.Lock()
-		cmd := p.cmd
-		gen := p.generation
-		p.mu.Unlock()
+// stopPaneSync stops the start command of the pane at index and runs its stop command. It reports
+// whether the stop command succeeded.
+func (v *View) stopPaneSync(index int) bool {
+	p := v.panes[index]
+	p.stopSetup()
 
-		v.tviewApp.QueueUpdate(func() {
-			_, _ = fmt.Fprintf(
-				p.textView,
-				"\n[gray]━━━ Stopping... %s ━━━[-]\n\n",
-				time.Now().Format("15:04:05"),
-			)
-		})
+	p.mu.Lock()
+	cmd := p.cmd
+	gen := p.generation
+	p.mu.Unlock()
+
+	v.queueUpdate(func() {
+		v.writePaneMessage(
+			p,
+			"\n[gray]━━━ Stopping... %s ━━━[-]\n\n",
+			time.Now().Format("15:04:05"),
+		)
+	})
 
-		if cmd != nil && cmd.Process != nil {
-			p.markExpectedStop(gen)
-			pid := cmd.Process.Pid
-			if err := unix.Kill(-pid, unix.SIGINT); err != nil && err != syscall.ESRCH {
-				logger.Warnf(
-					"failed to send SIGINT to process group for pane %s (pid=%d, pgid=%d): %v",
-					p.config.Name,
-					pid,
-					-pid,
-					err,
-				)
-			}
+	if cmd != nil && cmd.Process != nil {
+		p.markExpectedStop(gen)
+		v.terminatePaneProcessGroup(p, cmd.Process.Pid)
+	}
 
-			exited := false
-			for range 30 {
-				if err := unix.Kill(-pid, 0); err == syscall.ESRCH {
-					exited = true
-					break
-				}
-				time.Sleep(100 * time.Millisecond)
-			}
+	stopErrorCount := 0
+	if p.config.Stop != "" {
+		stopErrorCount = v.runPaneCommandToTextView(p, p.config.Stop)
+	}
 
-			if !exited {
-				if err := unix.Kill(-pid, unix.SIGKILL); err != nil && err != syscall.ESRCH {
-					logger.Warnf(
-						"failed to send SIGKILL to process group for pane %s (pid=%d, pgid=%d): %v",
-						p.config.Name,
-						pid,
-						-pid,
-						err,
-					)
-				}
-				for range 20 {
-					if err := unix.Kill(-pid, 0); err == syscall.ESRCH {
-						break
-					}
-					time.Sleep(100 * time.Millisecond)
-				}
-			}
+	p.mu.Lock()
+	p.stopExecuted = stopErrorCount == 0
+	p.mu.Unlock()
+
+	v.queueUpdate(func() {
+		if stopErrorCount == 0 {
+			v.writePaneMessage(
+				p,
+				"\n[gray]━━━ Stopped at %s ━━━[-]\n\n",
+				time.Now().Format("15:04:05"),
+			)
+		} else {
+			v.writePaneMessage(p, "\n[red]━━━ Stop command failed at %s (%d error(s)); final shutdown will retry cleanup ━━━[-]\n\n", time.Now().Format("15:04:05"), stopErrorCount)
 		}
+	})
 
-		stopErrorCount := v.runPaneCommandToTextView(
-			p.config.Name,
-			p.config.Dir,
-			p.config.Stop,
-			p.textView,
-		)
-
-		p.mu.Lock()
-		p.stopExecuted = stopErrorCount == 0
-		p.mu.Unlock()
-
-		v.tviewApp.QueueUpdate(func() {
-			if stopErrorCount == 0 {
-				_, _ = fmt.Fprintf(
-					p.textView,
-					"\n[gray]━━━ Stopped at %s ━━━[-]\n\n",
-					time.Now().Format("15:04:05"),
-				)
-			} else {
-				_, _ = fmt.Fprintf(p.textView, "\n[red]━━━ Stop command failed at %s (%d error(s)); final shutdown will retry cleanup ━━━[-]\n\n", time.Now().Format("15:04:05"), stopErrorCount)
-			}
-		})
-
-		v.tviewApp.QueueUpdate(func() {
-			v.updatePaneTitle(index)
-		})
-	}()
+	v.queueUpdate(func() {
+		v.updatePaneTitle(index)
+	})
+	return stopErrorCount == 0
 }
 
-func (v *View) runPaneCommandToTextView(
-	paneName, dir, userCmd string,
-	textView *tview.TextView,
-) int {
+// runPaneCommandToTextView runs userCmd in the pane directory, streams its output into the pane
+// and returns the number of errors encountered.
+func (v *View) runPaneCommandToTextView(pane *Pane, userCmd string) int {
+	paneName := pane.config.Name
 	var errorMu sync.Mutex
 	errorCount := 0
 	recordError := func() {
@@ -945,8 +1448,8 @@ func (v *View) runPaneCommandToTextView(
 
 	sh := shell.Current()
 	cmd := exec.Command(sh, "-c", userCmd)
-	cmd.Env = append(os.Environ(), v.envVars...)
-	cmd.Dir = dir
+	cmd.Env = v.paneCommandEnv(pane)
+	cmd.Dir = pane.config.Dir
 
 	stdout, err1 := cmd.StdoutPipe()
 	stderr, err2 := cmd.StderrPipe()
@@ -963,29 +1466,29 @@ func (v *View) runPaneCommandToTextView(
 		}
 		if err1 != nil {
 			logger.Errorf(
-				"error creating stdout pipe for stop command for pane %s: %v",
+				"error creating stdout pipe for command for pane %s: %v",
 				paneName,
 				err1,
 			)
 		}
 		if err2 != nil {
 			logger.Errorf(
-				"error creating stderr pipe for stop command for pane %s: %v",
+				"error creating stderr pipe for command for pane %s: %v",
 				paneName,
 				err2,
 			)
 		}
-		v.tviewApp.QueueUpdate(func() {
-			_, _ = fmt.Fprintf(textView, "[red]Error piping stop command: %v[-]\n", displayErr)
+		v.queueUpdate(func() {
+			v.writePaneMessage(pane, "[red]Error piping command: %v[-]\n", displayErr)
 		})
 		return errorCount
 	}
 
 	if err := cmd.Start(); err != nil {
 		recordError()
-		logger.Errorf("error starting stop command for pane %s: %v", paneName, err)
-		v.tviewApp.QueueUpdate(func() {
-			_, _ = fmt.Fprintf(textView, "[red]Error starting stop command: %v[-]\n", err)
+		logger.Errorf("error starting command for pane %s: %v", paneName, err)
+		v.queueUpdate(func() {
+			v.writePaneMessage(pane, "[red]Error starting command: %v[-]\n", err)
 		})
 		return errorCount
 	}
@@ -997,14 +1500,11 @@ func (v *View) runPaneCommandToTextView(
 		defer wg.Done()
 		scanner := bufio.NewScanner(stdout)
 		for scanner.Scan() {
-			t := scanner.Text()
-			v.tviewApp.QueueUpdate(func() {
-				_, _ = textView.Write([]byte(t + "\n"))
-			})
+			v.writePaneOutput(pane, scanner.Text(), false)
 		}
 		if err := scanner.Err(); err != nil {
 			recordError()
-			logger.Errorf("error reading stdout for pane %s during stop command: %v", paneName, err)
+			logger.Errorf("error reading stdout for pane %s during command: %v", paneName, err)
 		}
 	}()
 
@@ -1013,28 +1513,119 @@ func (v *View) runPaneCommandToTextView(
 		defer wg.Done()
 		scanner := bufio.NewScanner(stderr)
 		for scanner.Scan() {
-			t := scanner.Text()
-			v.tviewApp.QueueUpdate(func() {
-				_, _ = textView.Write([]byte("[#8B4513]" + t + "[white]\n"))
-			})
+			v.writePaneOutput(pane, scanner.Text(), true)
 		}
 		if err := scanner.Err(); err != nil {
 			recordError()
-			logger.Errorf("error reading stderr for pane %s during stop command: %v", paneName, err)
+			logger.Errorf("error reading stderr for pane %s during command: %v", paneName, err)
 		}
 	}()
 
+	// All reads must complete before Wait closes the pipes.
+	wg.Wait()
 	if err := cmd.Wait(); err != nil {
 		recordError()
-		logger.Errorf("stop command for pane %s exited with error: %v", paneName, err)
-		v.tviewApp.QueueUpdate(func() {
-			_, _ = fmt.Fprintf(textView, "[red]Pane command exited with error: %v[-]\n", err)
+		logger.Errorf("command for pane %s exited with error: %v", paneName, err)
+		v.queueUpdate(func() {
+			v.writePaneMessage(pane, "[red]Pane command exited with error: %v[-]\n", err)
 		})
 	}
-	wg.Wait()
 	return errorCount
 }
 
+// Stop makes Run return as if the user quit. It is safe to call from any goroutine, also before
+// Run has started the UI.
+func (v *View) Stop() {
+	v.stopMu.Lock()
+	v.stopRequested = true
+	app := v.tviewApp
+	v.stopMu.Unlock()
+	if app != nil {
+		// Queued so it also takes effect when the UI has not started yet; the queue is drained by
+		// Run, so do not block when the UI has already stopped.
+		go app.QueueUpdate(app.Stop)
+	}
+}
+
+// KillPanes sends SIGKILL to the start process group of every running pane and returns the
+// process groups it signaled.
+func (v *View) KillPanes() []PaneProcessGroup {
+	var killed []PaneProcessGroup
+	for i, p := range v.panes {
+		p.stopSetup()
+		p.mu.Lock()
+		cmd := p.cmd
+		gen := p.generation
+		p.mu.Unlock()
+		if cmd == nil || cmd.Process == nil {
+			continue
+		}
+		p.markExpectedStop(gen)
+		if err := unix.Kill(-cmd.Process.Pid, unix.SIGKILL); err != nil {
+			logger.Errorf("error killing process group %d of pane %s: %v", cmd.Process.Pid, p.config.Name, err)
+			continue
+		}
+		killed = append(killed, PaneProcessGroup{Index: i, Name: p.config.Name, Pgid: cmd.Process.Pid})
+	}
+	return killed
+}
+
+// PaneProcessGroup identifies the process group of a pane's start command.
+type PaneProcessGroup struct {
+	Index int
+	Name  string
+	Pgid  int
+}
+
+// TerminatePane stops the start process group of the pane at index, if it is running, with the
+// pane's stop sequence, calling onStep before each signal is sent. It returns the process group
+// signaled, with a zero Pgid when the pane was not running, and whether it is still running.
+func (v *View) TerminatePane(
+	index int,
+	onStep func(group PaneProcessGroup, step command.StopStep),
+) (PaneProcessGroup, bool) {
+	p := v.panes[index]
+	p.stopSetup()
+	p.mu.Lock()
+	cmd := p.cmd
+	gen := p.generation
+	p.mu.Unlock()
+	if cmd == nil || cmd.Process == nil {
+		return PaneProcessGroup{Index: index, Name: p.config.Name}, false
+	}
+
+	group := PaneProcessGroup{Index: index, Name: p.config.Name, Pgid: cmd.Process.Pid}
+	p.markExpectedStop(gen)
+	exited, err := command.TerminateProcessGroup(
+		group.Pgid,
+		PaneStopSteps(p.config),
+		func(step command.StopStep) { onStep(group, step) },
+	)
+	if err != nil {
+		logger.Errorf("error stopping process group %d of pane %s: %v", group.Pgid, group.Name, err)
+	}
+	return group, !exited
+}
+
+// terminateStartedPanes stops the panes started before startup failed, in reverse order, printing
+// each signal sent. It must be called after the UI has stopped.
+func (v *View) terminateStartedPanes() {
+	for i := len(v.panes) - 1; i >= 0; i-- {
+		group, stillRunning := v.TerminatePane(i, func(group PaneProcessGroup, step command.StopStep) {
+			fmt.Printf(
+				"[%s] Sending %s to process group %d (waiting up to %s)\n",
+				group.Name,
+				unix.SignalName(step.Signal),
+				group.Pgid,
+				step.Timeout,
+			)
+		})
+		if stillRunning {
+			logger.Errorf("process group %d of pane %s is still running", group.Pgid, group.Name)
+		}
+	}
+}
+
 // GetManuallyStoppedPaneNames returns a set of pane names that were manually stopped.
 func (v *View) GetManuallyStoppedPaneNames() map[string]bool {
 	result := make(map[string]bool)
@@ -1053,6 +1644,147 @@ func (v *View) updatePaneTitle(index int) {
 		return
 	}
 	p := v.panes[index]
-	focused := p.textView.HasFocus()
-	p.textView.SetTitle(getPaneTitle(index, p.config, focused, p.IsRunning()))
+	p.textView.SetTitle(v.paneTitle(p, index, p.textView.HasFocus()))
+}
+
+// paneTitle builds the title of the pane at index from its current state.
+// It must be called on the UI goroutine.
+func (v *View) paneTitle(p *Pane, index int, focused bool) string {
+	p.mu.Lock()
+	task := p.task
+	p.mu.Unlock()
+	return getPaneTitle(
+		index,
+		p.config,
+		focused,
+		p.IsRunning(),
+		task,
+		v.getGitStatus(p.config.Dir),
+		v.fetchingDirs[p.config.Dir],
+	)
+}
+
+// getGitStatus returns the cached git status of dir, or nil if it has not been collected yet.
+func (v *View) getGitStatus(dir string) *command.GitStatus {
+	if v.gitStatusPoller == nil {
+		return nil
+	}
+	status, ok := v.gitStatusPoller.Get(dir)
+	if !ok {
+		return nil
+	}
+	return &status
+}
+
+// handleGitFetchStart shows the fetching indicator on the panes of a repository being fetched.
+// It is called by the git fetcher from a background goroutine.
+func (v *View) handleGitFetchStart(dirs []string) {
+	v.queueUpdateDraw(func() {
+		if v.fetchingDirs == nil {
+			v.fetchingDirs = make(map[string]bool)
+		}
+		for _, dir := range dirs {
+			v.fetchingDirs[dir] = true
+		}
+		for i, p := range v.panes {
+			if slices.Contains(dirs, p.config.Dir) {
+				v.updatePaneTitle(i)
+			}
+		}
+	})
+}
+
+// handleGitFetchDone clears the fetching indicator and refreshes the git status of the fetched panes.
+// Failures are logged once until the fetch succeeds again rather than written to the panes.
+// It is called by the git fetcher from a background goroutine.
+func (v *View) handleGitFetchDone(dirs []string, err error) {
+	v.queueUpdateDraw(func() {
+		if v.fetchErrors == nil {
+			v.fetchErrors = make(map[string]string)
+		}
+		// The dirs share one repository and one fetch, so a change is logged once per call.
+		var failed, recovered bool
+		for _, dir := range dirs {
+			delete(v.fetchingDirs, dir)
+			switch {
+			case err != nil && v.fetchErrors[dir] != err.Error():
+				failed = true
+				v.fetchErrors[dir] = err.Error()
+			case err == nil && v.fetchErrors[dir] != "":
+				recovered = true
+				delete(v.fetchErrors, dir)
+			}
+		}
+		switch {
+		case failed:
+			logger.Warnf("background git fetch failed in %s: %v", strings.Join(dirs, ", "), err)
+		case recovered:
+			logger.Infof("background git fetch recovered in %s", strings.Join(dirs, ", "))
+		}
+		for i, p := range v.panes {
+			if slices.Contains(dirs, p.config.Dir) {
+				v.updatePaneTitle(i)
+			}
+		}
+	})
+	for _, dir := range dirs {
+		v.gitStatusPoller.Refresh(dir)
+	}
+}
+
+// handleGitStatusChange refreshes the titles of every pane running in dir and restarts panes
+// configured with restart_on_branch_change when the checked out branch changed.
+// It is called by the git status poller from a background goroutine.
+func (v *View) handleGitStatusChange(dir string) {
+	v.queueUpdateDraw(func() {
+		branch := settledBranch(v.getGitStatus(dir))
+		for i, p := range v.panes {
+			if p.config.Dir != dir {
+				continue
+			}
+			v.updatePaneTitle(i)
+
+			// A detached HEAD or an operation in progress, such as a rebase or bisect, moves HEAD
+			// through many commits; wait until HEAD settles on a branch again.
+			if branch == "" {
+				continue
+			}
+			previousBranch := p.lastBranch
+			p.lastBranch = branch
+			if !p.config.RestartOnBranchChange || previousBranch == "" || previousBranch == branch {
+				continue
+			}
+			p.mu.Lock()
+			manuallyStopped := p.stopExecuted
+			taskSucceeded := p.task.succeeded()
+			p.mu.Unlock()
+			// A task that already succeeded is only rerun on request.
+			if manuallyStopped || (p.config.IsTask() && taskSucceeded) {
+				continue
+			}
+			logger.Infof(
+				"restarting pane %s after branch change from %s to %s",
+				p.config.Name,
+				previousBranch,
+				branch,
+			)
+			v.writePaneMessage(
+				p,
+				"\n[gray]━━━ Branch changed from %s to %s at %s; restarting ━━━[-]\n",
+				tview.Escape(previousBranch),
+				tview.Escape(branch),
+				time.Now().Format("15:04:05"),
+			)
+			v.startPane(i)
+		}
+	})
+}
+
+// settledBranch returns the checked out branch name, or an empty string when the status is unknown,
+// dir is not a repository, HEAD is detached or an operation such as a rebase is in progress.
+func settledBranch(gitStatus *command.GitStatus) string {
+	if gitStatus == nil || !gitStatus.IsRepo || gitStatus.Operation != "" {
+		return ""
+	}
+	return gitStatus.Branch
 }
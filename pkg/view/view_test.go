@@ -1,16 +1,34 @@
 package view
 
 import (
+	"fmt"
+	"os"
 	"path/filepath"
 	"reflect"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/gdamore/tcell/v2"
+	"github.com/jiyeol-lee/localdev/pkg/command"
 	"github.com/jiyeol-lee/localdev/pkg/config"
+	"github.com/jiyeol-lee/localdev/pkg/constant"
+	"github.com/jiyeol-lee/localdev/pkg/internal/env_vars"
+	"github.com/jiyeol-lee/localdev/pkg/internal/env_vars/envvarstest"
+	"github.com/jiyeol-lee/localdev/pkg/internal/gittest"
+	"github.com/jiyeol-lee/localdev/pkg/internal/mask"
+	"github.com/jiyeol-lee/localdev/pkg/internal/session"
 	"github.com/rivo/tview"
+	"golang.org/x/sys/unix"
 )
 
+// TestMain lets the shell run this test binary as the env dump helper used by the project
+// command, as it does the localdev binary.
+func TestMain(m *testing.M) {
+	envvarstest.Main(m)
+}
+
 func Test_getGridDimensions(t *testing.T) {
 	type args struct {
 		length int
@@ -101,6 +119,64 @@ func Test_getGridDimensions(t *testing.T) {
 	}
 }
 
+func Test_formatGitStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   *command.GitStatus
+		fetching bool
+		want     string
+	}{
+		{name: "not collected yet", status: nil, want: "…"},
+		{name: "not a repository", status: &command.GitStatus{}, want: "N/A"},
+		{
+			name:   "clean branch without upstream",
+			status: &command.GitStatus{IsRepo: true, Branch: "main", HeadSHA: "abc1234"},
+			want:   "main",
+		},
+		{
+			name: "detached HEAD shows short SHA",
+			status: &command.GitStatus{
+				IsRepo:    true,
+				HeadSHA:   "abc1234",
+				Operation: "rebase",
+			},
+			want: "[orange](abc1234)[white] [red]REBASE[white]",
+		},
+		{
+			name: "upstream, changes and stashes",
+			status: &command.GitStatus{
+				IsRepo:    true,
+				Branch:    "feature",
+				Upstream:  "fork/feature",
+				Sync:      &command.BranchSyncStatus{Ahead: 1, Behind: 2},
+				Staged:    3,
+				Untracked: 4,
+				Stashes:   5,
+			},
+			want: "feature [gray]fork/feature[white] [yellow]↑1[white] [yellow]↓2[white]" +
+				" [green]+3[white] [gray]?4[white] [blue]≡5[white]",
+		},
+		{
+			name:   "brackets in branch and upstream are escaped",
+			status: &command.GitStatus{IsRepo: true, Branch: "fix/[wip]", Upstream: "origin/fix/[wip]"},
+			want:   "fix/[wip[] [gray]origin/fix/[wip[][white]",
+		},
+		{
+			name:     "fetch in progress",
+			status:   &command.GitStatus{IsRepo: true, Branch: "main"},
+			fetching: true,
+			want:     "main [gray]⟳[white]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatGitStatus(tt.status, tt.fetching); got != tt.want {
+				t.Errorf("formatGitStatus() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestView_getRootView_resolvesPaneDirWithProjectSettingsDir(t *testing.T) {
 	projectDir := t.TempDir()
 	paneDir := "service"
@@ -170,7 +246,11 @@ func TestView_runPaneCommandToTextView_ReturnsErrorCount(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			app := startTestTviewApplication(t)
 			v := &View{tviewApp: app}
-			got := v.runPaneCommandToTextView("test-pane", tt.dir, tt.command, tview.NewTextView())
+			pane := &Pane{
+				textView: tview.NewTextView(),
+				config:   config.ConfigPane{Name: "test-pane", Dir: tt.dir},
+			}
+			got := v.runPaneCommandToTextView(pane, tt.command)
 			if tt.wantErr && got == 0 {
 				t.Fatalf("error count = %d, want > 0", got)
 			}
@@ -181,6 +261,740 @@ func TestView_runPaneCommandToTextView_ReturnsErrorCount(t *testing.T) {
 	}
 }
 
+func TestView_writePaneOutput_KeepsRawLineInHistory(t *testing.T) {
+	app := startTestTviewApplication(t)
+	v := &View{tviewApp: app}
+	pane := &Pane{
+		textView: tview.NewTextView().SetDynamicColors(true),
+		config: config.ConfigPane{
+			Name:   "api",
+			Format: "json",
+		},
+	}
+
+	raw := `{"level":"info","msg":"ready"}`
+	v.writePaneOutput(pane, raw, false)
+	v.writePaneOutput(pane, "plain line", true)
+
+	if got, want := pane.history.text(), raw+"\nplain line\n"; got != want {
+		t.Fatalf("history = %q, want %q", got, want)
+	}
+	displayedCh := make(chan string, 1)
+	app.QueueUpdate(func() { displayedCh <- pane.textView.GetText(true) })
+	if displayed := <-displayedCh; displayed != "INFO ready\nplain line\n" {
+		t.Fatalf("displayed text = %q, want formatted JSON and raw fallback", displayed)
+	}
+}
+
+func TestView_savePaneOutput_WritesHistoryAndClearPaneWipesIt(t *testing.T) {
+	outputDir := filepath.Join(t.TempDir(), "output")
+	pane := &Pane{
+		textView: tview.NewTextView().SetDynamicColors(true),
+		config:   config.ConfigPane{Name: "api"},
+	}
+	v := &View{outputDir: outputDir, panes: []*Pane{pane}}
+	v.writePaneMessage(pane, "[gray]━━━ Started ━━━[-]\n")
+	pane.history.append(`{"msg":"raw"}` + "\n")
+
+	v.savePaneOutput(0)
+
+	files, err := os.ReadDir(outputDir)
+	if err != nil {
+		t.Fatalf("read output dir: %v", err)
+	}
+	if len(files) != 1 {
+		t.Fatalf("saved files = %d, want 1", len(files))
+	}
+	contents, err := os.ReadFile(filepath.Join(outputDir, files[0].Name()))
+	if err != nil {
+		t.Fatalf("read saved file: %v", err)
+	}
+	if got, want := string(contents), "━━━ Started ━━━\n{\"msg\":\"raw\"}\n"; got != want {
+		t.Fatalf("saved contents = %q, want %q", got, want)
+	}
+	if !strings.Contains(pane.textView.GetText(true), files[0].Name()) {
+		t.Fatalf("expected saved path to be printed in the pane, got %q", pane.textView.GetText(true))
+	}
+
+	v.savePaneOutput(0)
+	if files, err = os.ReadDir(outputDir); err != nil {
+		t.Fatalf("read output dir: %v", err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("saved files after second save = %d, want 2", len(files))
+	}
+
+	v.clearPane(0)
+	if got := pane.history.text(); got != "" {
+		t.Fatalf("history after clear = %q, want empty", got)
+	}
+	if got := pane.textView.GetText(true); got != "" {
+		t.Fatalf("text after clear = %q, want empty", got)
+	}
+}
+
+func TestView_writeEnvChangesSummary(t *testing.T) {
+	tests := []struct {
+		name       string
+		envChanges env_vars.EnvVarsChanges
+		want       string
+	}{
+		{name: "no changes", envChanges: env_vars.EnvVarsChanges{}, want: ""},
+		{
+			name: "lists names without values",
+			envChanges: env_vars.EnvVarsChanges{
+				Added:   []string{"API_TOKEN=secret"},
+				Changed: []string{"PATH=/bin"},
+				Removed: []string{"AWS_PROFILE"},
+			},
+			want: "\n  ===Project Environment===\n\n  + API_TOKEN\n  ~ PATH\n  - AWS_PROFILE\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := &View{envChanges: tt.envChanges}
+			tv := tview.NewTextView().SetDynamicColors(true)
+			v.writeEnvChangesSummary(tv)
+			if got := tv.GetText(true); got != tt.want {
+				t.Errorf("writeEnvChangesSummary() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestView_paneCommandEnv_Precedence(t *testing.T) {
+	t.Setenv("TEST_ENV_OS", "os")
+	t.Setenv("TEST_ENV_UNSET", "os")
+	t.Setenv("TEST_ENV_LAYER", "os")
+	v := &View{
+		envChanges: env_vars.EnvVarsChanges{
+			Changed: []string{"TEST_ENV_LAYER=project command"},
+			Removed: []string{"TEST_ENV_UNSET"},
+		},
+		projectEnv: []string{"TEST_ENV_LAYER=project env", "TEST_ENV_PROJECT=project env"},
+	}
+	api := &Pane{env: []string{"TEST_ENV_LAYER=pane env"}}
+	web := &Pane{}
+
+	lookup := func(env []string, name string) (string, bool) {
+		for _, kv := range env {
+			if k, value, _ := strings.Cut(kv, "="); k == name {
+				return value, true
+			}
+		}
+		return "", false
+	}
+	tests := []struct {
+		name string
+		env  []string
+		key  string
+		want string
+		set  bool
+	}{
+		{name: "os env is inherited", env: v.paneCommandEnv(api), key: "TEST_ENV_OS", want: "os", set: true},
+		{name: "project command unset wins over os", env: v.paneCommandEnv(api), key: "TEST_ENV_UNSET"},
+		{name: "pane env wins", env: v.paneCommandEnv(api), key: "TEST_ENV_LAYER", want: "pane env", set: true},
+		{name: "project env wins without pane env", env: v.paneCommandEnv(web), key: "TEST_ENV_LAYER", want: "project env", set: true},
+		{name: "project env applies to every pane", env: v.paneCommandEnv(api), key: "TEST_ENV_PROJECT", want: "project env", set: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := lookup(tt.env, tt.key)
+			if ok != tt.set || got != tt.want {
+				t.Errorf("%s = %q (set %v), want %q (set %v)", tt.key, got, ok, tt.want, tt.set)
+			}
+		})
+	}
+}
+
+func TestView_startPane_RunsSetupAndAppliesItsEnv(t *testing.T) {
+	app := startTestTviewApplication(t)
+	p := &Pane{
+		textView: tview.NewTextView(),
+		config: config.ConfigPane{
+			Name:  "api",
+			Dir:   t.TempDir(),
+			Setup: "echo activating; TEST_SETUP_VAR=from-setup; export TEST_SETUP_VAR",
+			Start: "echo \"start sees $TEST_SETUP_VAR\"; exec sleep 30",
+		},
+	}
+	t.Cleanup(func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		if p.cmd != nil && p.cmd.Process != nil {
+			_ = unix.Kill(-p.cmd.Process.Pid, unix.SIGKILL)
+		}
+	})
+	v := &View{tviewApp: app, panes: []*Pane{p}}
+
+	v.startPane(0)
+	waitForUI(t, app, func() bool {
+		return strings.Contains(p.history.text(), "start sees from-setup")
+	})
+
+	history := p.history.text()
+	if !strings.Contains(history, "Running setup") || !strings.Contains(history, "activating") {
+		t.Errorf("history = %q, want the setup separator and output", history)
+	}
+	if !slices.Contains(v.paneCommandEnv(p), "TEST_SETUP_VAR=from-setup") {
+		t.Error("paneCommandEnv() is missing the variable exported by the setup command")
+	}
+}
+
+func TestView_startPane_SetupFailureSkipsStart(t *testing.T) {
+	app := startTestTviewApplication(t)
+	p := &Pane{
+		textView: tview.NewTextView(),
+		config: config.ConfigPane{
+			Name:  "api",
+			Dir:   t.TempDir(),
+			Setup: "echo broken >&2; false",
+			Start: "echo started",
+		},
+	}
+	v := &View{tviewApp: app, panes: []*Pane{p}}
+
+	v.startPane(0)
+	waitForUI(t, app, func() bool {
+		return strings.Contains(p.history.text(), "Setup failed")
+	})
+
+	if history := p.history.text(); !strings.Contains(history, "broken") ||
+		strings.Contains(history, "started\n") {
+		t.Errorf("history = %q, want the setup output and no start output", history)
+	}
+}
+
+func TestView_stopPane_CancelsRunningSetup(t *testing.T) {
+	app := startTestTviewApplication(t)
+	p := &Pane{
+		textView: tview.NewTextView(),
+		config: config.ConfigPane{
+			Name:  "api",
+			Dir:   t.TempDir(),
+			Setup: "echo preparing; sleep 30",
+			Start: "echo started",
+		},
+	}
+	v := &View{tviewApp: app, panes: []*Pane{p}}
+
+	started := make(chan bool, 1)
+	go func() { started <- v.startPaneSync(0) }()
+	waitForUI(t, app, func() bool {
+		return strings.Contains(p.history.text(), "preparing")
+	})
+
+	v.stopPaneSync(0)
+	select {
+	case ok := <-started:
+		if ok {
+			t.Fatal("startPaneSync() = true, want false after the setup was canceled")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("setup command was not canceled by stop")
+	}
+	waitForUI(t, app, func() bool {
+		return strings.Contains(p.history.text(), "Setup canceled")
+	})
+	if history := p.history.text(); strings.Contains(history, "started\n") {
+		t.Errorf("history = %q, want no start output", history)
+	}
+}
+
+func TestView_writePaneOutput_MasksSecrets(t *testing.T) {
+	app := startTestTviewApplication(t)
+	masker, err := mask.New([]string{`ghp_[A-Za-z0-9]+`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := &View{
+		tviewApp:       app,
+		masker:         masker,
+		maskedEnvNames: constant.DefaultMaskedEnvNames,
+	}
+	v.addMaskedEnv([]string{"API_TOKEN=tok-12345", "DB_PASSWORD=hunter22", "PORT=3000"})
+	p := &Pane{textView: tview.NewTextView()}
+
+	v.writePaneOutput(p, "token=tok-12345 pass=hunter22 gh=ghp_abc port=3000", false)
+	want := "token=**** pass=**** gh=**** port=3000\n"
+	if got := p.history.text(); got != want {
+		t.Errorf("history = %q, want %q", got, want)
+	}
+	waitForUI(t, app, func() bool { return p.textView.GetText(true) == want })
+}
+
+func Test_paneStopSteps(t *testing.T) {
+	tests := []struct {
+		name string
+		pane config.ConfigPane
+		want []command.StopStep
+	}{
+		{
+			name: "defaults",
+			pane: config.ConfigPane{},
+			want: []command.StopStep{{Signal: unix.SIGINT, Timeout: constant.DefaultStopTimeout}},
+		},
+		{
+			name: "configured signal, timeout and escalation",
+			pane: config.ConfigPane{
+				StopSignal:  "term",
+				StopTimeout: 10 * time.Second,
+				StopEscalation: []config.StopStep{
+					{Signal: "SIGQUIT", Timeout: time.Second},
+					{Signal: "HUP"},
+				},
+			},
+			want: []command.StopStep{
+				{Signal: unix.SIGTERM, Timeout: 10 * time.Second},
+				{Signal: unix.SIGQUIT, Timeout: time.Second},
+				{Signal: unix.SIGHUP, Timeout: constant.DefaultStopTimeout},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PaneStopSteps(tt.pane); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("PaneStopSteps() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_formatTaskStatus(t *testing.T) {
+	started := time.Now()
+	tests := []struct {
+		name          string
+		task          taskRun
+		isRunning     bool
+		wantIndicator string
+		wantStatus    string
+	}{
+		{name: "not started", wantIndicator: "[yellow]●[white] "},
+		{name: "running", task: taskRun{startedAt: started}, isRunning: true, wantIndicator: "[yellow]●[white] "},
+		{
+			name:          "succeeded",
+			task:          taskRun{startedAt: started, finished: true, duration: 1234 * time.Millisecond},
+			wantIndicator: "[green]✓[white] ",
+			wantStatus:    " [green](ok in 1.2s)[white]",
+		},
+		{
+			name:          "failed",
+			task:          taskRun{startedAt: started, finished: true, exitCode: 2, duration: 90 * time.Second},
+			wantIndicator: "[red]✗[white] ",
+			wantStatus:    " [red](exit 2 after 1m30s)[white]",
+		},
+		{
+			name:          "stopped",
+			task:          taskRun{startedAt: started, finished: true, exitCode: -1, stopped: true, duration: time.Second},
+			wantIndicator: "[gray]■[white] ",
+			wantStatus:    " [gray](stopped after 1s)[white]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			indicator, status := formatTaskStatus(tt.task, tt.isRunning)
+			if indicator != tt.wantIndicator || status != tt.wantStatus {
+				t.Errorf(
+					"formatTaskStatus() = %q, %q, want %q, %q",
+					indicator,
+					status,
+					tt.wantIndicator,
+					tt.wantStatus,
+				)
+			}
+		})
+	}
+}
+
+func TestView_startPane_TaskReportsResult(t *testing.T) {
+	tests := []struct {
+		name        string
+		start       string
+		wantMessage string
+		wantExit    int
+	}{
+		{name: "success", start: "echo building", wantMessage: "Task succeeded in", wantExit: 0},
+		{name: "failure", start: "exit 2", wantMessage: "Task failed with exit code 2 after", wantExit: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := startTestTviewApplication(t)
+			p := &Pane{
+				textView: tview.NewTextView(),
+				config:   config.ConfigPane{Name: "proto", Dir: t.TempDir(), Start: tt.start, Type: "task"},
+			}
+			v := &View{tviewApp: app, panes: []*Pane{p}}
+
+			v.startPane(0)
+			waitForUI(t, app, func() bool { return strings.Contains(p.history.text(), tt.wantMessage) })
+
+			if history := p.history.text(); strings.Contains(history, "exited with error") {
+				t.Errorf("history = %q, want no start command error", history)
+			}
+			p.mu.Lock()
+			task := p.task
+			p.mu.Unlock()
+			if !task.finished || task.exitCode != tt.wantExit {
+				t.Errorf("task = %+v, want finished with exit code %d", task, tt.wantExit)
+			}
+		})
+	}
+}
+
+func Test_settledBranch(t *testing.T) {
+	tests := []struct {
+		name      string
+		gitStatus *command.GitStatus
+		want      string
+	}{
+		{name: "unknown status", gitStatus: nil, want: ""},
+		{name: "not a repository", gitStatus: &command.GitStatus{}, want: ""},
+		{name: "branch", gitStatus: &command.GitStatus{IsRepo: true, Branch: "main", HeadSHA: "abc1234"}, want: "main"},
+		{name: "detached HEAD", gitStatus: &command.GitStatus{IsRepo: true, HeadSHA: "abc1234"}, want: ""},
+		{
+			name:      "operation in progress",
+			gitStatus: &command.GitStatus{IsRepo: true, Branch: "main", HeadSHA: "abc1234", Operation: "bisect"},
+			want:      "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := settledBranch(tt.gitStatus); got != tt.want {
+				t.Errorf("settledBranch() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestView_handleGitStatusChange_RestartsPaneOnBranchChange(t *testing.T) {
+	repo := gittest.NewRepo(t)
+	gittest.Run(t, repo, "branch", "feature")
+	app := startTestTviewApplication(t)
+	restarted := &Pane{
+		textView: tview.NewTextView(),
+		config: config.ConfigPane{
+			Name:                  "api",
+			Dir:                   repo,
+			Start:                 "sleep 30",
+			RestartOnBranchChange: true,
+		},
+	}
+	t.Cleanup(func() {
+		restarted.mu.Lock()
+		defer restarted.mu.Unlock()
+		if restarted.cmd != nil && restarted.cmd.Process != nil {
+			_ = unix.Kill(-restarted.cmd.Process.Pid, unix.SIGKILL)
+		}
+	})
+	untouched := &Pane{
+		textView: tview.NewTextView(),
+		config:   config.ConfigPane{Name: "web", Dir: repo, Start: "true"},
+	}
+	v := &View{tviewApp: app, panes: []*Pane{restarted, untouched}}
+	v.gitStatusPoller = command.NewGitStatusPoller([]string{repo}, time.Hour, v.handleGitStatusChange)
+
+	v.gitStatusPoller.Refresh(repo)
+	waitForUI(t, app, func() bool { return restarted.lastBranch == "main" })
+
+	gittest.Run(t, repo, "checkout", "-q", "feature")
+	v.gitStatusPoller.Refresh(repo)
+	waitForUI(t, app, func() bool {
+		return strings.Contains(restarted.history.text(), "Started at")
+	})
+
+	if got := restarted.history.text(); !strings.Contains(got, "Branch changed from main to feature") {
+		t.Fatalf("history = %q, want branch change separator", got)
+	}
+	if got := untouched.history.text(); got != "" {
+		t.Fatalf("pane without restart_on_branch_change history = %q, want empty", got)
+	}
+}
+
+func TestView_handleGitStatusChange_IgnoresRebase(t *testing.T) {
+	repo := gittest.NewRepo(t)
+	gittest.Run(t, repo, "branch", "feature")
+	gittest.Run(t, repo, "commit", "-q", "--allow-empty", "-m", "main change")
+	gittest.Run(t, repo, "checkout", "-q", "feature")
+	gittest.Run(t, repo, "commit", "-q", "--allow-empty", "-m", "feature change")
+	t.Setenv("GIT_SEQUENCE_EDITOR", "sed -i s/^pick/edit/")
+
+	app := startTestTviewApplication(t)
+	pane := &Pane{
+		textView: tview.NewTextView(),
+		config: config.ConfigPane{
+			Name:                  "api",
+			Dir:                   repo,
+			Start:                 "sleep 30",
+			RestartOnBranchChange: true,
+		},
+	}
+	v := &View{tviewApp: app, panes: []*Pane{pane}}
+	v.gitStatusPoller = command.NewGitStatusPoller([]string{repo}, time.Hour, v.handleGitStatusChange)
+
+	v.gitStatusPoller.Refresh(repo)
+	waitForUI(t, app, func() bool { return pane.lastBranch == "feature" })
+
+	// Each step moves the detached HEAD to another commit while the rebase is in progress.
+	for _, args := range [][]string{
+		{"rebase", "-q", "-i", "--keep-empty", "main"},
+		{"commit", "-q", "--amend", "--allow-empty", "-m", "feature change amended"},
+		{"rebase", "--continue"},
+	} {
+		gittest.Run(t, repo, args...)
+		v.gitStatusPoller.Refresh(repo)
+		waitForUI(t, app, func() bool { return true })
+	}
+
+	if status := v.getGitStatus(repo); status.Branch != "feature" || status.Operation != "" {
+		t.Fatalf("git status = %+v, want feature with no operation", status)
+	}
+	if got := pane.history.text(); got != "" {
+		t.Fatalf("history = %q, want no restart", got)
+	}
+	if pane.lastBranch != "feature" {
+		t.Fatalf("lastBranch = %q, want feature", pane.lastBranch)
+	}
+}
+
+func TestView_TerminatePane_EscalatesAndSkipsStoppedPanes(t *testing.T) {
+	app := startTestTviewApplication(t)
+	running := &Pane{
+		textView: tview.NewTextView(),
+		config: config.ConfigPane{
+			Name:        "api",
+			Dir:         t.TempDir(),
+			Start:       "trap '' INT; echo ready; sleep 30",
+			StopTimeout: 200 * time.Millisecond,
+		},
+	}
+	exited := &Pane{
+		textView: tview.NewTextView(),
+		config:   config.ConfigPane{Name: "web", Dir: t.TempDir(), Start: "true"},
+	}
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	v := &View{
+		tviewApp: app,
+		panes:    []*Pane{running, exited},
+		session:  session.NewRecorder(statePath, "config.yml"),
+	}
+	t.Cleanup(func() {
+		running.mu.Lock()
+		defer running.mu.Unlock()
+		if running.cmd != nil && running.cmd.Process != nil {
+			_ = unix.Kill(-running.cmd.Process.Pid, unix.SIGKILL)
+		}
+	})
+
+	v.startPane(0)
+	v.startPane(1)
+	waitForUI(t, app, func() bool {
+		return strings.Contains(running.history.text(), "ready") && !exited.IsRunning() &&
+			strings.Contains(exited.history.text(), "Started at")
+	})
+
+	state, err := session.Load(statePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(state.Panes) != 1 || state.Panes[0].Name != "api" {
+		t.Fatalf("session state panes = %+v, want only the running pane", state.Panes)
+	}
+
+	var steps []string
+	onStep := func(group PaneProcessGroup, step command.StopStep) {
+		steps = append(steps, fmt.Sprintf("%s %s", group.Name, unix.SignalName(step.Signal)))
+	}
+	for i := range v.panes {
+		group, stillRunning := v.TerminatePane(i, onStep)
+		if stillRunning {
+			t.Errorf("TerminatePane(%d) = %+v still running", i, group)
+		}
+		if wantSignaled := i == 0; (group.Pgid != 0) != wantSignaled {
+			t.Errorf("TerminatePane(%d) pgid = %d, want signaled %v", i, group.Pgid, wantSignaled)
+		}
+	}
+	if want := []string{"api SIGINT", "api SIGKILL"}; !reflect.DeepEqual(steps, want) {
+		t.Errorf("steps = %v, want %v", steps, want)
+	}
+	// The session state file is updated before cmd is cleared.
+	waitForUI(t, app, func() bool {
+		running.mu.Lock()
+		defer running.mu.Unlock()
+		return running.cmd == nil
+	})
+}
+
+func TestView_TerminatePane_DrainsOutputAfterUIStopped(t *testing.T) {
+	stdout, err := os.Create(filepath.Join(t.TempDir(), "stdout"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stdout.Close()
+	origStdout := os.Stdout
+	os.Stdout = stdout
+	t.Cleanup(func() { os.Stdout = origStdout })
+
+	app := startTestTviewApplication(t)
+	line := strings.Repeat("x", 100)
+	pane := &Pane{
+		textView: tview.NewTextView(),
+		config: config.ConfigPane{
+			Name: "api",
+			Dir:  t.TempDir(),
+			// More output on SIGINT than a pipe buffers.
+			Start:       fmt.Sprintf("trap 'yes %s | head -n 1000; exit 0' INT; echo ready; while :; do sleep 1; done", line),
+			StopTimeout: 5 * time.Second,
+		},
+	}
+	v := &View{tviewApp: app, panes: []*Pane{pane}}
+	t.Cleanup(func() {
+		pane.mu.Lock()
+		defer pane.mu.Unlock()
+		if pane.cmd != nil && pane.cmd.Process != nil {
+			_ = unix.Kill(-pane.cmd.Process.Pid, unix.SIGKILL)
+		}
+	})
+
+	v.startPane(0)
+	waitForUI(t, app, func() bool { return strings.Contains(pane.history.text(), "ready") })
+	app.Stop()
+	close(v.uiStopped())
+
+	var steps []string
+	_, stillRunning := v.TerminatePane(0, func(group PaneProcessGroup, step command.StopStep) {
+		steps = append(steps, fmt.Sprintf("%s %s", group.Name, unix.SignalName(step.Signal)))
+	})
+	if stillRunning {
+		t.Error("TerminatePane() left the process group running")
+	}
+	if want := []string{"api SIGINT"}; !reflect.DeepEqual(steps, want) {
+		t.Errorf("steps = %v, want %v", steps, want)
+	}
+	output, err := os.ReadFile(stdout.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(output), "[api] "+line+"\n") {
+		t.Errorf("stdout = %.200q, want the pane output prefixed with the pane name", output)
+	}
+}
+
+func TestView_terminateStartedPanes(t *testing.T) {
+	stdout, err := os.Create(filepath.Join(t.TempDir(), "stdout"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stdout.Close()
+	origStdout := os.Stdout
+	os.Stdout = stdout
+	t.Cleanup(func() { os.Stdout = origStdout })
+
+	app := startTestTviewApplication(t)
+	started := &Pane{
+		textView: tview.NewTextView(),
+		config:   config.ConfigPane{Name: "api", Dir: t.TempDir(), Start: "echo ready; sleep 30"},
+	}
+	// The pane whose start failed.
+	failed := &Pane{
+		textView: tview.NewTextView(),
+		config:   config.ConfigPane{Name: "web", Dir: t.TempDir(), Start: "true"},
+	}
+	v := &View{tviewApp: app, panes: []*Pane{started, failed}}
+	t.Cleanup(func() {
+		started.mu.Lock()
+		defer started.mu.Unlock()
+		if started.cmd != nil && started.cmd.Process != nil {
+			_ = unix.Kill(-started.cmd.Process.Pid, unix.SIGKILL)
+		}
+	})
+
+	v.startPane(0)
+	waitForUI(t, app, func() bool { return strings.Contains(started.history.text(), "ready") })
+	app.Stop()
+	close(v.uiStopped())
+
+	v.terminateStartedPanes()
+	if started.IsRunning() {
+		t.Error("terminateStartedPanes() left the started pane running")
+	}
+	output, err := os.ReadFile(stdout.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(output); !strings.HasPrefix(got, "[api] Sending SIGINT to process group ") ||
+		strings.Contains(got, "[web]") {
+		t.Errorf("stdout = %q, want only the signal sent to the started pane", got)
+	}
+}
+
+func TestView_Stop(t *testing.T) {
+	t.Run("before Run", func(t *testing.T) {
+		v := &View{}
+		v.Stop()
+		err := v.Run(config.Config{Panes: []config.ConfigPane{{Name: "api", Dir: t.TempDir(), Start: "true"}}})
+		if err == nil || !strings.Contains(err.Error(), "stopped before startup") {
+			t.Fatalf("Run() error = %v, want stopped before startup", err)
+		}
+	})
+
+	t.Run("while running", func(t *testing.T) {
+		screen := tcell.NewSimulationScreen("")
+		if err := screen.Init(); err != nil {
+			t.Fatal(err)
+		}
+		app := tview.NewApplication().SetScreen(screen)
+		done := make(chan error, 1)
+		go func() { done <- app.Run() }()
+		v := &View{tviewApp: app}
+
+		v.Stop()
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Fatalf("app.Run() error = %v", err)
+			}
+		case <-time.After(2 * time.Second):
+			app.Stop()
+			t.Fatal("timed out waiting for Stop to stop the app")
+		}
+	})
+}
+
+func TestView_KillPanes(t *testing.T) {
+	app := startTestTviewApplication(t)
+	p := &Pane{
+		textView: tview.NewTextView(),
+		config:   config.ConfigPane{Name: "api", Dir: t.TempDir(), Start: "trap '' INT TERM; echo ready; sleep 30"},
+	}
+	v := &View{tviewApp: app, panes: []*Pane{p}}
+
+	v.startPane(0)
+	waitForUI(t, app, func() bool { return strings.Contains(p.history.text(), "ready") })
+
+	killed := v.KillPanes()
+	if len(killed) != 1 || killed[0].Name != "api" {
+		t.Fatalf("KillPanes() = %+v, want the api pane", killed)
+	}
+	waitForUI(t, app, func() bool { return !p.IsRunning() })
+	if strings.Contains(p.history.text(), "exited with error") {
+		t.Errorf("history = %q, want the kill treated as an expected stop", p.history.text())
+	}
+}
+
+func TestPaneHistory_TrimsToMaxLines(t *testing.T) {
+	var h paneHistory
+	for i := range constant.MaxPaneHistoryLines + 10 {
+		h.append(fmt.Sprintf("line %d\n", i))
+	}
+	lines := strings.Split(strings.TrimSuffix(h.text(), "\n"), "\n")
+	if len(lines) != constant.MaxPaneHistoryLines {
+		t.Fatalf("history length = %d, want %d", len(lines), constant.MaxPaneHistoryLines)
+	}
+	if lines[0] != "line 10" {
+		t.Fatalf("first history line = %q, want %q", lines[0], "line 10")
+	}
+}
+
 func TestView_GetManuallyStoppedPaneNamesOnlyIncludesSuccessfulStops(t *testing.T) {
 	v := &View{panes: []*Pane{
 		{config: config.ConfigPane{Name: "stopped"}, stopExecuted: true},